@@ -17,6 +17,7 @@ limitations under the License.
 package v1
 
 import (
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -24,14 +25,189 @@ import (
 // NOTE: json tags are required.  Any new fields you add must have json tags for the fields to be serialized.
 
 // EmailSpec defines the desired state of Email
+// +kubebuilder:validation:XValidation:rule="has(self.senderConfigRef) != has(self.senderPoolRef)",message="exactly one of senderConfigRef and senderPoolRef must be set"
+// +kubebuilder:validation:XValidation:rule="has(self.subject) || has(self.templateRef) || has(self.providerTemplate)",message="subject must be set unless templateRef or providerTemplate is set"
+// +kubebuilder:validation:XValidation:rule="has(self.body) || has(self.html) || has(self.templateRef) || has(self.providerTemplate)",message="at least one of body and html must be set"
+// +kubebuilder:validation:XValidation:rule="!has(self.providerTemplate) || !(has(self.body) || has(self.html) || has(self.templateRef) || has(self.format) || has(self.layout))",message="body, html, templateRef, format and layout must not be set with providerTemplate"
+// +kubebuilder:validation:XValidation:rule="!has(self.format) || self.format != 'markdown' || !has(self.html)",message="html must not be set when format is markdown"
+// +kubebuilder:validation:XValidation:rule="has(self.recipientEmail) || has(self.to) || has(self.cc) || has(self.bcc)",message="at least one recipient must be set"
 type EmailSpec struct {
 	// INSERT ADDITIONAL SPEC FIELDS - desired state of cluster
 	// Important: Run "make" to regenerate code after modifying this file
 
-	SenderConfigRef string `json:"senderConfigRef"`
-	RecipientEmail  string `json:"recipientEmail"`
-	Subject         string `json:"subject"`
-	Body            string `json:"body"`
+	// +optional
+	SenderConfigRef string `json:"senderConfigRef,omitempty"`
+	// SenderPoolRef names an EmailSenderPool the sender config is picked
+	// from, in place of SenderConfigRef.
+	// +optional
+	SenderPoolRef string `json:"senderPoolRef,omitempty"`
+	// RecipientEmail is a single recipient, added to To.
+	// +optional
+	RecipientEmail string `json:"recipientEmail,omitempty"`
+	// +optional
+	To []EmailRecipient `json:"to,omitempty"`
+	// +optional
+	Cc []EmailRecipient `json:"cc,omitempty"`
+	// +optional
+	Bcc []EmailRecipient `json:"bcc,omitempty"`
+	// Subject, Body and HTML are rendered as Go templates when TemplateData
+	// is set, HTML with html/template. They take precedence over those of
+	// the template referenced by TemplateRef.
+	// +optional
+	Subject string `json:"subject,omitempty"`
+	// Body is the plain text body. When only HTML is set, it is generated
+	// from the HTML.
+	// +optional
+	Body string `json:"body,omitempty"`
+	// HTML is the HTML body, sent together with the plain text body as
+	// multipart/alternative.
+	// +optional
+	HTML string `json:"html,omitempty"`
+	// Format is the format of Body. A Markdown body is rendered to the HTML
+	// body and a matching plain text body.
+	// +kubebuilder:validation:Enum=text;markdown
+	// +optional
+	Format string `json:"format,omitempty"`
+	// Layout wraps the HTML body in one of the built-in layouts.
+	// +kubebuilder:validation:Enum=basic;card
+	// +optional
+	Layout string `json:"layout,omitempty"`
+	// MessageOptions take precedence over those of the EmailSenderConfig.
+	MessageOptions `json:",inline"`
+	// Attachments are read from ConfigMaps and Secrets in the namespace of
+	// the Email when it is sent.
+	// +optional
+	Attachments []EmailAttachment `json:"attachments,omitempty"`
+	// TemplateRef names the EmailTemplate or ClusterEmailTemplate the
+	// subject and bodies are rendered from.
+	// +optional
+	TemplateRef *EmailTemplateReference `json:"templateRef,omitempty"`
+	// TemplateData lists the values the subject and bodies are rendered
+	// with, each available to the templates as {{ .<name> }}.
+	// +optional
+	TemplateData []EmailTemplateData `json:"templateData,omitempty"`
+	// ProviderTemplate references a template stored by the provider, in
+	// place of Body and HTML. Subject, when set, overrides the subject of
+	// the template for providers that support it.
+	// +optional
+	ProviderTemplate *ProviderTemplate `json:"providerTemplate,omitempty"`
+}
+
+// ProviderTemplate is a template stored by the provider together with the
+// variables it is rendered with.
+type ProviderTemplate struct {
+	// ID is the template as the provider identifies it: the template ID for
+	// MailerSend and SendGrid, the template name for Mailgun and SES, and
+	// the template ID or alias for Postmark.
+	// +kubebuilder:validation:MinLength=1
+	ID string `json:"id"`
+	// Variables are substituted into the template by the provider.
+	// +optional
+	Variables map[string]string `json:"variables,omitempty"`
+}
+
+// EmailTemplateReference names an EmailTemplate in the namespace of the
+// Email or a ClusterEmailTemplate.
+type EmailTemplateReference struct {
+	// +kubebuilder:validation:Enum=EmailTemplate;ClusterEmailTemplate
+	// +kubebuilder:default=EmailTemplate
+	// +optional
+	Kind string `json:"kind,omitempty"`
+	Name string `json:"name"`
+}
+
+// EmailTemplateData is a named template value, given inline or read from a
+// ConfigMap key, a Secret key or an object when the email is sent.
+// +kubebuilder:validation:XValidation:rule="[has(self.value), has(self.configMapKeyRef), has(self.secretKeyRef), has(self.objectRef)].filter(x, x).size() == 1",message="exactly one of value, configMapKeyRef, secretKeyRef and objectRef must be set"
+type EmailTemplateData struct {
+	// +kubebuilder:validation:Pattern=`^[A-Za-z_][A-Za-z0-9_]*$`
+	Name string `json:"name"`
+	// +optional
+	Value string `json:"value,omitempty"`
+	// +optional
+	ConfigMapKeyRef *corev1.ConfigMapKeySelector `json:"configMapKeyRef,omitempty"`
+	// +optional
+	SecretKeyRef *corev1.SecretKeySelector `json:"secretKeyRef,omitempty"`
+	// +optional
+	ObjectRef *TemplateObjectReference `json:"objectRef,omitempty"`
+}
+
+// TemplateObjectReference selects a field of any object in the namespace of
+// the Email, or of a cluster scoped object.
+type TemplateObjectReference struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Name       string `json:"name"`
+	// JSONPath selects the value, such as .spec.template.spec.containers[0].image.
+	// The whole object is used when it is empty.
+	// +optional
+	JSONPath string `json:"jsonPath,omitempty"`
+}
+
+// MessageOptions set the sender display name, the reply address, custom
+// headers and the tags and metadata passed to the provider.
+type MessageOptions struct {
+	// SenderName is the display name of the sender.
+	// +optional
+	SenderName string `json:"senderName,omitempty"`
+	// ReplyTo is the address replies are sent to, optionally with a display
+	// name such as "Support <support@example.com>".
+	// +optional
+	ReplyTo string `json:"replyTo,omitempty"`
+	// Headers are added to the message. Headers set by the operator, such as
+	// From, To or Subject, cannot be set.
+	// +optional
+	Headers []EmailHeader `json:"headers,omitempty"`
+	// Tags are passed to the provider to group messages in its analytics.
+	// +optional
+	Tags []string `json:"tags,omitempty"`
+	// Metadata is passed to the provider as custom variables of the message.
+	// +optional
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+// EmailHeader is a custom message header.
+type EmailHeader struct {
+	// +kubebuilder:validation:Pattern=`^[!-9;-~]+$`
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// Formats of the body of an Email.
+const (
+	FormatText     = "text"
+	FormatMarkdown = "markdown"
+)
+
+// EmailAttachment is a file attached to the email, read from a key of a
+// ConfigMap or a Secret.
+// +kubebuilder:validation:XValidation:rule="has(self.configMapKeyRef) != has(self.secretKeyRef)",message="exactly one of configMapKeyRef and secretKeyRef must be set"
+type EmailAttachment struct {
+	Filename string `json:"filename"`
+	// ContentType defaults to the type of the filename extension.
+	// +optional
+	ContentType string `json:"contentType,omitempty"`
+	// ContentID embeds the attachment inline, the HTML body refers to it
+	// as cid:<contentId>.
+	// +optional
+	ContentID string `json:"contentId,omitempty"`
+	// +optional
+	ConfigMapKeyRef *corev1.ConfigMapKeySelector `json:"configMapKeyRef,omitempty"`
+	// +optional
+	SecretKeyRef *corev1.SecretKeySelector `json:"secretKeyRef,omitempty"`
+}
+
+// EmailRecipient is a recipient address with an optional display name.
+type EmailRecipient struct {
+	// +optional
+	Name  string `json:"name,omitempty"`
+	Email string `json:"email"`
+}
+
+// RejectedRecipient is a recipient the email was not sent to.
+type RejectedRecipient struct {
+	Email  string `json:"email"`
+	Reason string `json:"reason"`
 }
 
 // EmailStatus defines the observed state of Email
@@ -41,7 +217,22 @@ type EmailStatus struct {
 
 	DeliveryStatus string `json:"deliveryStatus"`
 	MessageId      string `json:"messageId"`
-	Error          string `json:"error"`
+	// ThreadId is the thread the message was added to, for providers with threads.
+	// +optional
+	ThreadId string `json:"threadId,omitempty"`
+	Error    string `json:"error"`
+	// SenderConfig is the EmailSenderConfig that delivered the message.
+	// +optional
+	SenderConfig string `json:"senderConfig,omitempty"`
+	// Provider is the provider that delivered the message.
+	// +optional
+	Provider string `json:"provider,omitempty"`
+	// AcceptedRecipients lists the recipients the provider accepted the message for.
+	// +optional
+	AcceptedRecipients []string `json:"acceptedRecipients,omitempty"`
+	// RejectedRecipients lists the invalid recipients and those refused by the provider.
+	// +optional
+	RejectedRecipients []RejectedRecipient `json:"rejectedRecipients,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -27,9 +27,126 @@ import (
 type EmailSenderConfigSpec struct {
 	// INSERT ADDITIONAL SPEC FIELDS - desired state of cluster
 	// Important: Run "make" to regenerate code after modifying this file
-	Provider          string `json:"provider"`
-	ApiTokenSecretRef string `json:"apiTokenSecretRef"`
+	Provider string `json:"provider"`
+	// ApiTokenSecretRef is the name of the Secret holding the provider credentials.
+	// +optional
+	ApiTokenSecretRef string `json:"apiTokenSecretRef,omitempty"`
 	SenderEmail       string `json:"senderEmail"`
+	// Fallbacks lists EmailSenderConfigs in the same namespace that are tried
+	// in order when sending through this one fails.
+	// +optional
+	Fallbacks []string `json:"fallbacks,omitempty"`
+	// Timeout bounds every request made to the provider, such as "30s".
+	// Defaults to 10s.
+	// +optional
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+	// Transport configures the proxy and TLS settings of the connections to
+	// the provider, overriding the defaults of the manager.
+	// +optional
+	Transport *TransportConfig `json:"transport,omitempty"`
+	// MessageIDDomain is the domain of the Message-IDs generated for
+	// providers sending full MIME messages. Defaults to the domain of
+	// SenderEmail.
+	// +kubebuilder:validation:Pattern=`^[A-Za-z0-9]([A-Za-z0-9.-]*[A-Za-z0-9])?$`
+	// +optional
+	MessageIDDomain string `json:"messageIdDomain,omitempty"`
+	// MessageOptions apply to every email sent through this config, the
+	// options of an Email take precedence.
+	MessageOptions `json:",inline"`
+
+	// MailerSend configures the MailerSend provider.
+	// +optional
+	MailerSend *MailerSendConfig `json:"mailerSend,omitempty"`
+	// Mailgun configures the Mailgun provider.
+	// +optional
+	Mailgun *MailgunConfig `json:"mailgun,omitempty"`
+	// SMTP configures the SMTP provider.
+	// +optional
+	SMTP *SMTPConfig `json:"smtp,omitempty"`
+	// SendGrid configures the SendGrid provider.
+	// +optional
+	SendGrid *SendGridConfig `json:"sendGrid,omitempty"`
+	// SES configures the Amazon SES provider.
+	// +optional
+	SES *SESConfig `json:"ses,omitempty"`
+	// Postmark configures the Postmark provider.
+	// +optional
+	Postmark *PostmarkConfig `json:"postmark,omitempty"`
+	// Graph configures the Microsoft Graph provider.
+	// +optional
+	Graph *GraphConfig `json:"graph,omitempty"`
+	// Gmail configures the Gmail provider.
+	// +optional
+	Gmail *GmailConfig `json:"gmail,omitempty"`
+	// Webhook configures the Webhook provider.
+	// +optional
+	Webhook *WebhookConfig `json:"webhook,omitempty"`
+	// File configures the File provider.
+	// +optional
+	File *FileConfig `json:"file,omitempty"`
+	// Maildir configures the Maildir provider.
+	// +optional
+	Maildir *MaildirConfig `json:"maildir,omitempty"`
+}
+
+// TransportConfig defines how connections to the provider are made.
+type TransportConfig struct {
+	// ProxyURL routes HTTP requests through a proxy, such as http://proxy.internal:3128.
+	// +optional
+	ProxyURL string `json:"proxyURL,omitempty"`
+	// ProxySecretRef is the name of a Secret holding the username and
+	// password keys used to authenticate to the proxy.
+	// +optional
+	ProxySecretRef string `json:"proxySecretRef,omitempty"`
+	// CABundleConfigMapRef is the name of a ConfigMap whose ca.crt key holds
+	// PEM encoded certificates trusted in addition to the system roots.
+	// +optional
+	CABundleConfigMapRef string `json:"caBundleConfigMapRef,omitempty"`
+	// ClientCertSecretRef is the name of a kubernetes.io/tls Secret whose
+	// certificate is presented to servers requesting one.
+	// +optional
+	ClientCertSecretRef string `json:"clientCertSecretRef,omitempty"`
+}
+
+// MailerSendConfig defines how to reach the MailerSend API.
+type MailerSendConfig struct {
+	// BaseURL overrides the MailerSend API base URL, defaults to https://api.mailersend.com/v1.
+	// +optional
+	BaseURL string `json:"baseURL,omitempty"`
+}
+
+// MailgunConfig defines how to reach the Mailgun API.
+type MailgunConfig struct {
+	// Domain is the Mailgun sending domain, such as mg.example.com. Defaults
+	// to the domain of the sender address.
+	// +optional
+	Domain string `json:"domain,omitempty"`
+	// Region selects the Mailgun API of the account region.
+	// +kubebuilder:validation:Enum=US;EU
+	// +kubebuilder:default=US
+	// +optional
+	Region string `json:"region,omitempty"`
+	// BaseURL overrides the API base URL of the region, such as https://api.eu.mailgun.net/v3.
+	// +optional
+	BaseURL string `json:"baseURL,omitempty"`
+}
+
+// SMTPConfig defines how to reach an SMTP relay. The username and password
+// used for SMTP AUTH are read from the referenced Secret.
+type SMTPConfig struct {
+	Host string `json:"host"`
+	// Port defaults to 25, 587 or 465 depending on the TLS mode.
+	// +optional
+	Port int32 `json:"port,omitempty"`
+	// +kubebuilder:validation:Enum=None;STARTTLS;Implicit
+	// +kubebuilder:default=STARTTLS
+	// +optional
+	TLSMode string `json:"tlsMode,omitempty"`
+	// AuthMechanism selects the SMTP AUTH mechanism, no authentication is
+	// performed when it is empty.
+	// +kubebuilder:validation:Enum=PLAIN;LOGIN;CRAM-MD5
+	// +optional
+	AuthMechanism string `json:"authMechanism,omitempty"`
 }
 
 // EmailSenderConfigStatus defines the observed state of EmailSenderConfig
@@ -40,6 +157,107 @@ type EmailSenderConfigStatus struct {
 	Valid bool `json:"valid"`
 }
 
+// SendGridConfig defines how to reach the SendGrid v3 API.
+type SendGridConfig struct {
+	// BaseURL overrides the SendGrid API base URL, defaults to https://api.sendgrid.com.
+	// +optional
+	BaseURL string `json:"baseURL,omitempty"`
+}
+
+// SESConfig defines how to reach the Amazon SES v2 API. The accessKeyId,
+// secretAccessKey and optional sessionToken keys are read from the
+// referenced Secret.
+type SESConfig struct {
+	Region string `json:"region"`
+	// Endpoint overrides the regional SES endpoint, defaults to https://email.<region>.amazonaws.com.
+	// +optional
+	Endpoint string `json:"endpoint,omitempty"`
+}
+
+// PostmarkConfig defines how to reach the Postmark API.
+type PostmarkConfig struct {
+	// MessageStream selects the Postmark message stream, such as "outbound" or "broadcast".
+	// +kubebuilder:default=outbound
+	// +optional
+	MessageStream string `json:"messageStream,omitempty"`
+	// BaseURL overrides the Postmark API base URL, defaults to https://api.postmarkapp.com.
+	// +optional
+	BaseURL string `json:"baseURL,omitempty"`
+}
+
+// GraphConfig defines how to reach Microsoft Graph. The tenantId, clientId
+// and clientSecret keys are read from the referenced Secret.
+type GraphConfig struct {
+	// AuthorityURL overrides the OAuth2 authority, defaults to https://login.microsoftonline.com.
+	// +optional
+	AuthorityURL string `json:"authorityURL,omitempty"`
+	// BaseURL overrides the Graph API base URL, defaults to https://graph.microsoft.com.
+	// +optional
+	BaseURL string `json:"baseURL,omitempty"`
+}
+
+// GmailConfig defines how to reach the Gmail API. The service account key is
+// read from the serviceAccountKey key of the referenced Secret and the
+// service account impersonates the sender through domain-wide delegation.
+type GmailConfig struct {
+	// TokenURL overrides the token endpoint of the service account key.
+	// +optional
+	TokenURL string `json:"tokenURL,omitempty"`
+	// BaseURL overrides the Gmail API base URL, defaults to https://gmail.googleapis.com.
+	// +optional
+	BaseURL string `json:"baseURL,omitempty"`
+}
+
+// WebhookConfig defines an HTTP request sent for every message.
+type WebhookConfig struct {
+	URL string `json:"url"`
+	// +kubebuilder:validation:Enum=POST;PUT;PATCH
+	// +kubebuilder:default=POST
+	// +optional
+	Method string `json:"method,omitempty"`
+	// +optional
+	Headers []WebhookHeader `json:"headers,omitempty"`
+	// BodyTemplate is a Go template rendered with .From, .To, .Subject,
+	// .Body and .HTML. The json function encodes a value as JSON. Defaults to
+	// a JSON object holding these fields, html only when it is set.
+	// +optional
+	BodyTemplate string `json:"bodyTemplate,omitempty"`
+	// MessageIDPath is a JSONPath expression, such as {.id}, extracting the
+	// message id from the response body. The braces may be left out.
+	// +optional
+	MessageIDPath string `json:"messageIdPath,omitempty"`
+}
+
+// WebhookHeader is a request header, either set inline or read from a key of
+// the referenced Secret.
+type WebhookHeader struct {
+	Name string `json:"name"`
+	// +optional
+	Value string `json:"value,omitempty"`
+	// SecretKey is the key of the referenced Secret holding the header value.
+	// +optional
+	SecretKey string `json:"secretKey,omitempty"`
+}
+
+// FileConfig defines where the File provider writes messages.
+type FileConfig struct {
+	// Directory is a mounted directory the messages are written to.
+	Directory string `json:"directory"`
+	// Format is either eml, writing one .eml file per message, or mbox,
+	// appending every message to messages.mbox.
+	// +kubebuilder:validation:Enum=eml;mbox
+	// +kubebuilder:default=eml
+	// +optional
+	Format string `json:"format,omitempty"`
+}
+
+// MaildirConfig defines where the Maildir provider delivers messages.
+type MaildirConfig struct {
+	// Directory is the root of the Maildir, its cur, new and tmp
+	// subdirectories are created when missing.
+	Directory string `json:"directory"`
+}
+
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
 
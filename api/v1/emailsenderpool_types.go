@@ -0,0 +1,64 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// EmailSenderPoolSpec defines the desired state of EmailSenderPool
+type EmailSenderPoolSpec struct {
+	// Members are the EmailSenderConfigs an Email referencing the pool is
+	// sent through, picked at random in proportion to their weight.
+	// +kubebuilder:validation:MinItems=1
+	Members []EmailSenderPoolMember `json:"members"`
+}
+
+// EmailSenderPoolMember is an EmailSenderConfig in the same namespace with
+// its share of the traffic.
+type EmailSenderPoolMember struct {
+	SenderConfigRef string `json:"senderConfigRef"`
+	// Weight is the relative share of emails sent through this member, 1
+	// when unset. A weight of 0 drains the member.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:default=1
+	// +optional
+	Weight *int32 `json:"weight,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// EmailSenderPool is the Schema for the emailsenderpools API
+type EmailSenderPool struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec EmailSenderPoolSpec `json:"spec,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// EmailSenderPoolList contains a list of EmailSenderPool
+type EmailSenderPoolList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []EmailSenderPool `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&EmailSenderPool{}, &EmailSenderPoolList{})
+}
@@ -0,0 +1,73 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// EmailTemplateSpec defines the desired state of EmailTemplate
+// +kubebuilder:validation:XValidation:rule="has(self.text) || has(self.html)",message="at least one of text and html must be set"
+type EmailTemplateSpec struct {
+	// Subject, Text and HTML are Go templates rendered with the template data
+	// of the Email, HTML with html/template.
+	Subject string `json:"subject"`
+	// Text is the plain text body. When only HTML is set, it is generated
+	// from the HTML.
+	// +optional
+	Text string `json:"text,omitempty"`
+	// +optional
+	HTML string `json:"html,omitempty"`
+	// Variables declares the template data the templates expect.
+	// +optional
+	Variables []EmailTemplateVariable `json:"variables,omitempty"`
+}
+
+// EmailTemplateVariable is a template value expected from the Email.
+type EmailTemplateVariable struct {
+	// +kubebuilder:validation:Pattern=`^[A-Za-z_][A-Za-z0-9_]*$`
+	Name string `json:"name"`
+	// Default is used when the Email does not set the variable.
+	// +optional
+	Default string `json:"default,omitempty"`
+	// Required variables must be set by every Email using the template.
+	// +optional
+	Required bool `json:"required,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// EmailTemplate is the Schema for the emailtemplates API
+type EmailTemplate struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec EmailTemplateSpec `json:"spec,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// EmailTemplateList contains a list of EmailTemplate
+type EmailTemplateList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []EmailTemplate `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&EmailTemplate{}, &EmailTemplateList{})
+}
@@ -21,16 +21,76 @@ limitations under the License.
 package v1
 
 import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterEmailTemplate) DeepCopyInto(out *ClusterEmailTemplate) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterEmailTemplate.
+func (in *ClusterEmailTemplate) DeepCopy() *ClusterEmailTemplate {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterEmailTemplate)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterEmailTemplate) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterEmailTemplateList) DeepCopyInto(out *ClusterEmailTemplateList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ClusterEmailTemplate, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterEmailTemplateList.
+func (in *ClusterEmailTemplateList) DeepCopy() *ClusterEmailTemplateList {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterEmailTemplateList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterEmailTemplateList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Email) DeepCopyInto(out *Email) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Spec = in.Spec
-	out.Status = in.Status
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Email.
@@ -51,6 +111,46 @@ func (in *Email) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EmailAttachment) DeepCopyInto(out *EmailAttachment) {
+	*out = *in
+	if in.ConfigMapKeyRef != nil {
+		in, out := &in.ConfigMapKeyRef, &out.ConfigMapKeyRef
+		*out = new(corev1.ConfigMapKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SecretKeyRef != nil {
+		in, out := &in.SecretKeyRef, &out.SecretKeyRef
+		*out = new(corev1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EmailAttachment.
+func (in *EmailAttachment) DeepCopy() *EmailAttachment {
+	if in == nil {
+		return nil
+	}
+	out := new(EmailAttachment)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EmailHeader) DeepCopyInto(out *EmailHeader) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EmailHeader.
+func (in *EmailHeader) DeepCopy() *EmailHeader {
+	if in == nil {
+		return nil
+	}
+	out := new(EmailHeader)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *EmailList) DeepCopyInto(out *EmailList) {
 	*out = *in
@@ -83,12 +183,27 @@ func (in *EmailList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EmailRecipient) DeepCopyInto(out *EmailRecipient) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EmailRecipient.
+func (in *EmailRecipient) DeepCopy() *EmailRecipient {
+	if in == nil {
+		return nil
+	}
+	out := new(EmailRecipient)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *EmailSenderConfig) DeepCopyInto(out *EmailSenderConfig) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Spec = in.Spec
+	in.Spec.DeepCopyInto(&out.Spec)
 	out.Status = in.Status
 }
 
@@ -145,6 +260,77 @@ func (in *EmailSenderConfigList) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *EmailSenderConfigSpec) DeepCopyInto(out *EmailSenderConfigSpec) {
 	*out = *in
+	if in.Fallbacks != nil {
+		in, out := &in.Fallbacks, &out.Fallbacks
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Timeout != nil {
+		in, out := &in.Timeout, &out.Timeout
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.Transport != nil {
+		in, out := &in.Transport, &out.Transport
+		*out = new(TransportConfig)
+		**out = **in
+	}
+	in.MessageOptions.DeepCopyInto(&out.MessageOptions)
+	if in.MailerSend != nil {
+		in, out := &in.MailerSend, &out.MailerSend
+		*out = new(MailerSendConfig)
+		**out = **in
+	}
+	if in.Mailgun != nil {
+		in, out := &in.Mailgun, &out.Mailgun
+		*out = new(MailgunConfig)
+		**out = **in
+	}
+	if in.SMTP != nil {
+		in, out := &in.SMTP, &out.SMTP
+		*out = new(SMTPConfig)
+		**out = **in
+	}
+	if in.SendGrid != nil {
+		in, out := &in.SendGrid, &out.SendGrid
+		*out = new(SendGridConfig)
+		**out = **in
+	}
+	if in.SES != nil {
+		in, out := &in.SES, &out.SES
+		*out = new(SESConfig)
+		**out = **in
+	}
+	if in.Postmark != nil {
+		in, out := &in.Postmark, &out.Postmark
+		*out = new(PostmarkConfig)
+		**out = **in
+	}
+	if in.Graph != nil {
+		in, out := &in.Graph, &out.Graph
+		*out = new(GraphConfig)
+		**out = **in
+	}
+	if in.Gmail != nil {
+		in, out := &in.Gmail, &out.Gmail
+		*out = new(GmailConfig)
+		**out = **in
+	}
+	if in.Webhook != nil {
+		in, out := &in.Webhook, &out.Webhook
+		*out = new(WebhookConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.File != nil {
+		in, out := &in.File, &out.File
+		*out = new(FileConfig)
+		**out = **in
+	}
+	if in.Maildir != nil {
+		in, out := &in.Maildir, &out.Maildir
+		*out = new(MaildirConfig)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EmailSenderConfigSpec.
@@ -172,9 +358,149 @@ func (in *EmailSenderConfigStatus) DeepCopy() *EmailSenderConfigStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EmailSenderPool) DeepCopyInto(out *EmailSenderPool) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EmailSenderPool.
+func (in *EmailSenderPool) DeepCopy() *EmailSenderPool {
+	if in == nil {
+		return nil
+	}
+	out := new(EmailSenderPool)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *EmailSenderPool) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EmailSenderPoolList) DeepCopyInto(out *EmailSenderPoolList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]EmailSenderPool, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EmailSenderPoolList.
+func (in *EmailSenderPoolList) DeepCopy() *EmailSenderPoolList {
+	if in == nil {
+		return nil
+	}
+	out := new(EmailSenderPoolList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *EmailSenderPoolList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EmailSenderPoolMember) DeepCopyInto(out *EmailSenderPoolMember) {
+	*out = *in
+	if in.Weight != nil {
+		in, out := &in.Weight, &out.Weight
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EmailSenderPoolMember.
+func (in *EmailSenderPoolMember) DeepCopy() *EmailSenderPoolMember {
+	if in == nil {
+		return nil
+	}
+	out := new(EmailSenderPoolMember)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EmailSenderPoolSpec) DeepCopyInto(out *EmailSenderPoolSpec) {
+	*out = *in
+	if in.Members != nil {
+		in, out := &in.Members, &out.Members
+		*out = make([]EmailSenderPoolMember, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EmailSenderPoolSpec.
+func (in *EmailSenderPoolSpec) DeepCopy() *EmailSenderPoolSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(EmailSenderPoolSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *EmailSpec) DeepCopyInto(out *EmailSpec) {
 	*out = *in
+	if in.To != nil {
+		in, out := &in.To, &out.To
+		*out = make([]EmailRecipient, len(*in))
+		copy(*out, *in)
+	}
+	if in.Cc != nil {
+		in, out := &in.Cc, &out.Cc
+		*out = make([]EmailRecipient, len(*in))
+		copy(*out, *in)
+	}
+	if in.Bcc != nil {
+		in, out := &in.Bcc, &out.Bcc
+		*out = make([]EmailRecipient, len(*in))
+		copy(*out, *in)
+	}
+	in.MessageOptions.DeepCopyInto(&out.MessageOptions)
+	if in.Attachments != nil {
+		in, out := &in.Attachments, &out.Attachments
+		*out = make([]EmailAttachment, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.TemplateRef != nil {
+		in, out := &in.TemplateRef, &out.TemplateRef
+		*out = new(EmailTemplateReference)
+		**out = **in
+	}
+	if in.TemplateData != nil {
+		in, out := &in.TemplateData, &out.TemplateData
+		*out = make([]EmailTemplateData, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ProviderTemplate != nil {
+		in, out := &in.ProviderTemplate, &out.ProviderTemplate
+		*out = new(ProviderTemplate)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EmailSpec.
@@ -190,6 +516,16 @@ func (in *EmailSpec) DeepCopy() *EmailSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *EmailStatus) DeepCopyInto(out *EmailStatus) {
 	*out = *in
+	if in.AcceptedRecipients != nil {
+		in, out := &in.AcceptedRecipients, &out.AcceptedRecipients
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.RejectedRecipients != nil {
+		in, out := &in.RejectedRecipients, &out.RejectedRecipients
+		*out = make([]RejectedRecipient, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EmailStatus.
@@ -201,3 +537,425 @@ func (in *EmailStatus) DeepCopy() *EmailStatus {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EmailTemplate) DeepCopyInto(out *EmailTemplate) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EmailTemplate.
+func (in *EmailTemplate) DeepCopy() *EmailTemplate {
+	if in == nil {
+		return nil
+	}
+	out := new(EmailTemplate)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *EmailTemplate) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EmailTemplateData) DeepCopyInto(out *EmailTemplateData) {
+	*out = *in
+	if in.ConfigMapKeyRef != nil {
+		in, out := &in.ConfigMapKeyRef, &out.ConfigMapKeyRef
+		*out = new(corev1.ConfigMapKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SecretKeyRef != nil {
+		in, out := &in.SecretKeyRef, &out.SecretKeyRef
+		*out = new(corev1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ObjectRef != nil {
+		in, out := &in.ObjectRef, &out.ObjectRef
+		*out = new(TemplateObjectReference)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EmailTemplateData.
+func (in *EmailTemplateData) DeepCopy() *EmailTemplateData {
+	if in == nil {
+		return nil
+	}
+	out := new(EmailTemplateData)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EmailTemplateList) DeepCopyInto(out *EmailTemplateList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]EmailTemplate, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EmailTemplateList.
+func (in *EmailTemplateList) DeepCopy() *EmailTemplateList {
+	if in == nil {
+		return nil
+	}
+	out := new(EmailTemplateList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *EmailTemplateList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EmailTemplateReference) DeepCopyInto(out *EmailTemplateReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EmailTemplateReference.
+func (in *EmailTemplateReference) DeepCopy() *EmailTemplateReference {
+	if in == nil {
+		return nil
+	}
+	out := new(EmailTemplateReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EmailTemplateSpec) DeepCopyInto(out *EmailTemplateSpec) {
+	*out = *in
+	if in.Variables != nil {
+		in, out := &in.Variables, &out.Variables
+		*out = make([]EmailTemplateVariable, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EmailTemplateSpec.
+func (in *EmailTemplateSpec) DeepCopy() *EmailTemplateSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(EmailTemplateSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EmailTemplateVariable) DeepCopyInto(out *EmailTemplateVariable) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EmailTemplateVariable.
+func (in *EmailTemplateVariable) DeepCopy() *EmailTemplateVariable {
+	if in == nil {
+		return nil
+	}
+	out := new(EmailTemplateVariable)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FileConfig) DeepCopyInto(out *FileConfig) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FileConfig.
+func (in *FileConfig) DeepCopy() *FileConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(FileConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GmailConfig) DeepCopyInto(out *GmailConfig) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GmailConfig.
+func (in *GmailConfig) DeepCopy() *GmailConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(GmailConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GraphConfig) DeepCopyInto(out *GraphConfig) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GraphConfig.
+func (in *GraphConfig) DeepCopy() *GraphConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(GraphConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MaildirConfig) DeepCopyInto(out *MaildirConfig) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MaildirConfig.
+func (in *MaildirConfig) DeepCopy() *MaildirConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(MaildirConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MailerSendConfig) DeepCopyInto(out *MailerSendConfig) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MailerSendConfig.
+func (in *MailerSendConfig) DeepCopy() *MailerSendConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(MailerSendConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MailgunConfig) DeepCopyInto(out *MailgunConfig) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MailgunConfig.
+func (in *MailgunConfig) DeepCopy() *MailgunConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(MailgunConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MessageOptions) DeepCopyInto(out *MessageOptions) {
+	*out = *in
+	if in.Headers != nil {
+		in, out := &in.Headers, &out.Headers
+		*out = make([]EmailHeader, len(*in))
+		copy(*out, *in)
+	}
+	if in.Tags != nil {
+		in, out := &in.Tags, &out.Tags
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Metadata != nil {
+		in, out := &in.Metadata, &out.Metadata
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MessageOptions.
+func (in *MessageOptions) DeepCopy() *MessageOptions {
+	if in == nil {
+		return nil
+	}
+	out := new(MessageOptions)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PostmarkConfig) DeepCopyInto(out *PostmarkConfig) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostmarkConfig.
+func (in *PostmarkConfig) DeepCopy() *PostmarkConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(PostmarkConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProviderTemplate) DeepCopyInto(out *ProviderTemplate) {
+	*out = *in
+	if in.Variables != nil {
+		in, out := &in.Variables, &out.Variables
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProviderTemplate.
+func (in *ProviderTemplate) DeepCopy() *ProviderTemplate {
+	if in == nil {
+		return nil
+	}
+	out := new(ProviderTemplate)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RejectedRecipient) DeepCopyInto(out *RejectedRecipient) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RejectedRecipient.
+func (in *RejectedRecipient) DeepCopy() *RejectedRecipient {
+	if in == nil {
+		return nil
+	}
+	out := new(RejectedRecipient)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SESConfig) DeepCopyInto(out *SESConfig) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SESConfig.
+func (in *SESConfig) DeepCopy() *SESConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(SESConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SMTPConfig) DeepCopyInto(out *SMTPConfig) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SMTPConfig.
+func (in *SMTPConfig) DeepCopy() *SMTPConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(SMTPConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SendGridConfig) DeepCopyInto(out *SendGridConfig) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SendGridConfig.
+func (in *SendGridConfig) DeepCopy() *SendGridConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(SendGridConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TemplateObjectReference) DeepCopyInto(out *TemplateObjectReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TemplateObjectReference.
+func (in *TemplateObjectReference) DeepCopy() *TemplateObjectReference {
+	if in == nil {
+		return nil
+	}
+	out := new(TemplateObjectReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TransportConfig) DeepCopyInto(out *TransportConfig) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TransportConfig.
+func (in *TransportConfig) DeepCopy() *TransportConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(TransportConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WebhookConfig) DeepCopyInto(out *WebhookConfig) {
+	*out = *in
+	if in.Headers != nil {
+		in, out := &in.Headers, &out.Headers
+		*out = make([]WebhookHeader, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WebhookConfig.
+func (in *WebhookConfig) DeepCopy() *WebhookConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(WebhookConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WebhookHeader) DeepCopyInto(out *WebhookHeader) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WebhookHeader.
+func (in *WebhookHeader) DeepCopy() *WebhookHeader {
+	if in == nil {
+		return nil
+	}
+	out := new(WebhookHeader)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -19,12 +19,14 @@ package main
 import (
 	"crypto/tls"
 	"flag"
+	"fmt"
 	"os"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 
+	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
@@ -35,7 +37,10 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 
 	parhamv1 "github.com/parhamds/Email-Operator/api/v1"
+	"github.com/parhamds/Email-Operator/internal/address"
 	"github.com/parhamds/Email-Operator/internal/controller"
+	"github.com/parhamds/Email-Operator/internal/provider"
+	"github.com/parhamds/Email-Operator/internal/provider/capture"
 	// +kubebuilder:scaffold:imports
 )
 
@@ -57,6 +62,12 @@ func main() {
 	var probeAddr string
 	var secureMetrics bool
 	var enableHTTP2 bool
+	var captureAddr string
+	var transport provider.TransportOptions
+	var caBundleFile, clientCertFile, clientKeyFile string
+	var maxAttachmentSize string
+	var addressPolicy address.Policy
+	var plusAddressing, roleAccounts string
 	flag.StringVar(&metricsAddr, "metrics-bind-address", "0", "The address the metric endpoint binds to. "+
 		"Use the port :8080. If not set, it will be 0 in order to disable the metrics server")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
@@ -67,6 +78,23 @@ func main() {
 		"If set the metrics endpoint is served securely")
 	flag.BoolVar(&enableHTTP2, "enable-http2", false,
 		"If set, HTTP/2 will be enabled for the metrics and webhook servers")
+	flag.StringVar(&captureAddr, "capture-bind-address", "0", "The address the captured messages API binds to. "+
+		"Messages sent through the Capture provider are served on /api/messages. Use 0 to disable it")
+	flag.StringVar(&transport.ProxyURL, "proxy-url", "", "The HTTP proxy providers connect through unless their "+
+		"EmailSenderConfig sets one. Proxy credentials can be given as user info of the URL")
+	flag.StringVar(&caBundleFile, "ca-bundle", "", "A PEM file of certificates providers trust in addition to the system roots")
+	flag.StringVar(&clientCertFile, "client-cert", "", "A PEM client certificate presented to providers requesting one")
+	flag.StringVar(&clientKeyFile, "client-key", "", "The PEM private key of --client-cert")
+	flag.StringVar(&maxAttachmentSize, "max-attachment-size", "10Mi", "The maximum total size of the attachments of an Email, "+
+		"as a quantity such as 25Mi")
+	flag.StringVar(&plusAddressing, "plus-addressing", "allow", "Whether recipient addresses with a +tag are "+
+		"allowed, forbidden or required: allow, forbid or require")
+	flag.StringVar(&roleAccounts, "role-accounts", "allow", "Whether recipient role accounts such as "+
+		"info@ or support@ are allowed, forbidden or required: allow, forbid or require")
+	flag.IntVar(&addressPolicy.MaxLength, "max-address-length", address.DefaultMaxLength,
+		"The maximum length of recipient addresses")
+	flag.BoolVar(&addressPolicy.SMTPUTF8, "smtputf8", false, "If set, recipient addresses with non-ASCII local parts "+
+		"are accepted. They can only be delivered by providers supporting SMTPUTF8")
 	opts := zap.Options{
 		Development: true,
 	}
@@ -75,6 +103,44 @@ func main() {
 
 	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
 
+	for _, f := range []struct {
+		name string
+		data *[]byte
+	}{
+		{caBundleFile, &transport.CABundle},
+		{clientCertFile, &transport.ClientCert},
+		{clientKeyFile, &transport.ClientKey},
+	} {
+		if f.name == "" {
+			continue
+		}
+		var err error
+		if *f.data, err = os.ReadFile(f.name); err != nil {
+			setupLog.Error(err, "unable to read transport file", "file", f.name)
+			os.Exit(1)
+		}
+	}
+	if _, err := transport.HTTPClient(); err != nil {
+		setupLog.Error(err, "invalid transport configuration")
+		os.Exit(1)
+	}
+
+	attachmentLimit, err := resource.ParseQuantity(maxAttachmentSize)
+	if err == nil && attachmentLimit.Sign() <= 0 {
+		err = fmt.Errorf("max attachment size %s must be positive", maxAttachmentSize)
+	}
+	if err != nil {
+		setupLog.Error(err, "invalid maximum attachment size", "max-attachment-size", maxAttachmentSize)
+		os.Exit(1)
+	}
+
+	addressPolicy.PlusAddressing = address.Rule(plusAddressing)
+	addressPolicy.RoleAccounts = address.Rule(roleAccounts)
+	if err := addressPolicy.Validate(); err != nil {
+		setupLog.Error(err, "invalid recipient address policy")
+		os.Exit(1)
+	}
+
 	// if the enable-http2 flag is false (the default), http/2 should be disabled
 	// due to its vulnerabilities. More specifically, disabling http/2 will
 	// prevent from being vulnerable to the HTTP/2 Stream Cancellation and
@@ -124,21 +190,32 @@ func main() {
 	}
 
 	if err = (&controller.EmailReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
+		Client:            mgr.GetClient(),
+		Scheme:            mgr.GetScheme(),
+		Transport:         transport,
+		MaxAttachmentSize: attachmentLimit.Value(),
+		AddressPolicy:     addressPolicy,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Email")
 		os.Exit(1)
 	}
 	if err = (&controller.EmailSenderConfigReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
+		Client:    mgr.GetClient(),
+		Scheme:    mgr.GetScheme(),
+		Transport: transport,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "EmailSenderConfig")
 		os.Exit(1)
 	}
 	// +kubebuilder:scaffold:builder
 
+	if captureAddr != "0" {
+		if err := mgr.Add(&capture.Server{Addr: captureAddr, Store: capture.DefaultStore}); err != nil {
+			setupLog.Error(err, "unable to set up captured messages server")
+			os.Exit(1)
+		}
+	}
+
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
 		setupLog.Error(err, "unable to set up health check")
 		os.Exit(1)
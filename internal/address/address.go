@@ -0,0 +1,207 @@
+// Package address parses recipient addresses and checks them against a
+// configurable policy.
+package address
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/net/idna"
+)
+
+// ErrInvalid is returned for addresses that do not parse, and wrapped with
+// the reason for addresses the policy rejects.
+var ErrInvalid = errors.New("email must be a valid email address")
+
+// DefaultMaxLength is the longest address allowed in the forward-path of
+// an SMTP transaction.
+const DefaultMaxLength = 254
+
+// maxLocalPartLength is the longest local part allowed by RFC 5321.
+const maxLocalPartLength = 64
+
+// DefaultRoleAccounts are the local parts treated as role accounts when a
+// Policy does not list its own.
+var DefaultRoleAccounts = []string{
+	"abuse", "admin", "administrator", "billing", "contact", "help",
+	"hostmaster", "info", "marketing", "noc", "no-reply", "noreply",
+	"postmaster", "sales", "security", "support", "webmaster",
+}
+
+// Rule is how a Policy treats a kind of address.
+type Rule string
+
+const (
+	// Allow accepts addresses whether or not they are of the kind.
+	Allow Rule = "allow"
+	// Forbid rejects addresses of the kind.
+	Forbid Rule = "forbid"
+	// Require rejects addresses not of the kind.
+	Require Rule = "require"
+)
+
+// domains converts domains to their ASCII form, validating them for
+// lookup in the DNS.
+var domains = idna.New(
+	idna.MapForLookup(),
+	idna.BidiRule(),
+	idna.VerifyDNSLength(true),
+)
+
+// Policy decides which addresses are accepted. Its zero value accepts every
+// valid address of at most DefaultMaxLength characters with an ASCII local
+// part.
+type Policy struct {
+	// PlusAddressing is the rule for local parts with a +tag, Allow when
+	// empty.
+	PlusAddressing Rule
+	// RoleAccounts is the rule for the local parts of RoleAccountNames,
+	// Allow when empty.
+	RoleAccounts Rule
+	// RoleAccountNames are the local parts of role accounts, compared
+	// without case and +tag. DefaultRoleAccounts is used when it is empty.
+	RoleAccountNames []string
+	// MaxLength bounds the length of the address in bytes, DefaultMaxLength
+	// is used when it is zero.
+	MaxLength int
+	// SMTPUTF8 allows non-ASCII local parts, which can only be delivered
+	// through servers supporting the SMTPUTF8 extension.
+	SMTPUTF8 bool
+}
+
+// Validate returns an error for rules other than Allow, Forbid and Require.
+func (p *Policy) Validate() error {
+	for _, r := range []struct {
+		name string
+		rule Rule
+	}{
+		{"plus addressing", p.PlusAddressing},
+		{"role accounts", p.RoleAccounts},
+	} {
+		switch r.rule {
+		case "", Allow, Forbid, Require:
+		default:
+			return fmt.Errorf("unknown %s rule %q", r.name, r.rule)
+		}
+	}
+	if p.MaxLength < 0 {
+		return fmt.Errorf("maximum address length %d is negative", p.MaxLength)
+	}
+	return nil
+}
+
+// Parse parses s, a bare address or one with a display name, and checks it
+// against the policy. The domain of the returned address is converted to
+// its ASCII form when it is internationalized.
+func (p *Policy) Parse(s string) (*mail.Address, error) {
+	addr, err := mail.ParseAddress(s)
+	if err != nil {
+		return nil, ErrInvalid
+	}
+
+	i := strings.LastIndex(addr.Address, "@")
+	local, domain := addr.Address[:i], addr.Address[i+1:]
+	ascii, err := domains.ToASCII(domain)
+	if err != nil || !validDomain(ascii) {
+		return nil, fmt.Errorf("%w: invalid domain %q", ErrInvalid, domain)
+	}
+	if !isASCII(domain) {
+		domain = ascii
+	}
+	addr.Address = local + "@" + domain
+
+	if len(local) > maxLocalPartLength {
+		return nil, fmt.Errorf("%w: local part is longer than %d characters", ErrInvalid, maxLocalPartLength)
+	}
+	if !dotAtom(local) {
+		return nil, fmt.Errorf("%w: quoted local parts are not supported", ErrInvalid)
+	}
+	if !isASCII(local) && !p.SMTPUTF8 {
+		return nil, fmt.Errorf("%w: non-ASCII local parts require SMTPUTF8", ErrInvalid)
+	}
+	maxLength := p.MaxLength
+	if maxLength == 0 {
+		maxLength = DefaultMaxLength
+	}
+	if len(addr.Address) > maxLength {
+		return nil, fmt.Errorf("%w: address is longer than %d characters", ErrInvalid, maxLength)
+	}
+
+	base, _, plus := strings.Cut(local, "+")
+	plus = plus && base != ""
+	if err := check(p.PlusAddressing, plus, "plus addressing"); err != nil {
+		return nil, err
+	}
+	if err := check(p.RoleAccounts, p.isRoleAccount(base), "role account"); err != nil {
+		return nil, err
+	}
+	return addr, nil
+}
+
+// Parse parses s with the zero Policy.
+func Parse(s string) (*mail.Address, error) {
+	return (&Policy{}).Parse(s)
+}
+
+// check applies rule to an address that is or is not of the kind.
+func check(rule Rule, is bool, kind string) error {
+	switch {
+	case rule == Forbid && is:
+		return fmt.Errorf("%w: %s is not allowed", ErrInvalid, kind)
+	case rule == Require && !is:
+		return fmt.Errorf("%w: %s is required", ErrInvalid, kind)
+	}
+	return nil
+}
+
+func (p *Policy) isRoleAccount(local string) bool {
+	names := p.RoleAccountNames
+	if len(names) == 0 {
+		names = DefaultRoleAccounts
+	}
+	for _, name := range names {
+		if strings.EqualFold(local, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// validDomain reports whether domain has at least two labels and a top
+// level label that is not numeric, ruling out IP addresses.
+func validDomain(domain string) bool {
+	i := strings.LastIndex(domain, ".")
+	if i <= 0 || i == len(domain)-1 {
+		return false
+	}
+	return strings.Trim(domain[i+1:], "0123456789") != ""
+}
+
+// dotAtom reports whether local is a dot-atom, a local part that can be
+// used without quoting. Non-ASCII characters are left to SMTPUTF8.
+func dotAtom(local string) bool {
+	for _, atom := range strings.Split(local, ".") {
+		if atom == "" {
+			return false
+		}
+		for i := 0; i < len(atom); i++ {
+			c := atom[i]
+			if c < utf8.RuneSelf && !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || strings.IndexByte("!#$%&'*+-/=?^_`{|}~", c) >= 0) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
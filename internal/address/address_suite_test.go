@@ -0,0 +1,14 @@
+package address_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestAddress(t *testing.T) {
+	RegisterFailHandler(Fail)
+
+	RunSpecs(t, "Address Suite")
+}
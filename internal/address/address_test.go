@@ -0,0 +1,83 @@
+package address_test
+
+import (
+	"net/mail"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/parhamds/Email-Operator/internal/address"
+)
+
+var _ = Describe("Policy", func() {
+	DescribeTable("should accept valid addresses",
+		func(s string, want mail.Address) {
+			addr, err := address.Parse(s)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(*addr).To(Equal(want))
+		},
+		Entry("mixed case", "John.Doe@Example.com", mail.Address{Address: "John.Doe@Example.com"}),
+		Entry("display name", `"Doe, John" <john@example.com>`, mail.Address{Name: "Doe, John", Address: "john@example.com"}),
+		Entry("internationalized domain", "user@bücher.de", mail.Address{Address: "user@xn--bcher-kva.de"}),
+		Entry("punycode domain", "user@xn--bcher-kva.de", mail.Address{Address: "user@xn--bcher-kva.de"}),
+		Entry("plus addressing", "jane+news@example.co.uk", mail.Address{Address: "jane+news@example.co.uk"}),
+	)
+
+	DescribeTable("should reject invalid addresses",
+		func(s, reason string) {
+			_, err := address.Parse(s)
+			Expect(err).To(MatchError(address.ErrInvalid))
+			Expect(err).To(MatchError(reason))
+		},
+		Entry("no domain", "not-an-email", "email must be a valid email address"),
+		Entry("list", "a@example.com, b@example.com", "email must be a valid email address"),
+		Entry("single label domain", "jane@localhost", `email must be a valid email address: invalid domain "localhost"`),
+		Entry("IP address", "jane@[192.0.2.1]", `email must be a valid email address: invalid domain "[192.0.2.1]"`),
+		Entry("numeric top level domain", "jane@192.0.2.1", `email must be a valid email address: invalid domain "192.0.2.1"`),
+		Entry("hyphenated label", "jane@-example-.com", `email must be a valid email address: invalid domain "-example-.com"`),
+		Entry("bad punycode", "jane@xn--zz.com", `email must be a valid email address: invalid domain "xn--zz.com"`),
+		Entry("quoted local part", `"jane doe"@example.com`, "email must be a valid email address: quoted local parts are not supported"),
+		Entry("long local part", strings.Repeat("a", 65)+"@example.com", "email must be a valid email address: local part is longer than 64 characters"),
+		Entry("non ASCII local part", "jürgen@example.com", "email must be a valid email address: non-ASCII local parts require SMTPUTF8"),
+	)
+
+	It("should accept non ASCII local parts with SMTPUTF8", func() {
+		addr, err := (&address.Policy{SMTPUTF8: true}).Parse("Jürgen <jürgen@müller.de>")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(*addr).To(Equal(mail.Address{Name: "Jürgen", Address: "jürgen@xn--mller-kva.de"}))
+	})
+
+	It("should enforce the maximum length", func() {
+		long := strings.Repeat("a", 40) + "@" + strings.Repeat("b", 40) + ".com"
+		_, err := address.Parse(long)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = (&address.Policy{MaxLength: 64}).Parse(long)
+		Expect(err).To(MatchError("email must be a valid email address: address is longer than 64 characters"))
+	})
+
+	DescribeTable("should apply the plus addressing and role account rules",
+		func(policy address.Policy, s, reason string) {
+			_, err := policy.Parse(s)
+			if reason == "" {
+				Expect(err).NotTo(HaveOccurred())
+			} else {
+				Expect(err).To(MatchError("email must be a valid email address: " + reason))
+			}
+		},
+		Entry("allowed plus addressing", address.Policy{PlusAddressing: address.Allow}, "jane+news@example.com", ""),
+		Entry("forbidden plus addressing", address.Policy{PlusAddressing: address.Forbid}, "jane+news@example.com", "plus addressing is not allowed"),
+		Entry("leading plus", address.Policy{PlusAddressing: address.Forbid}, "+jane@example.com", ""),
+		Entry("required plus addressing", address.Policy{PlusAddressing: address.Require}, "jane@example.com", "plus addressing is required"),
+		Entry("forbidden role account", address.Policy{RoleAccounts: address.Forbid}, "Support+tickets@example.com", "role account is not allowed"),
+		Entry("personal account", address.Policy{RoleAccounts: address.Forbid}, "jane@example.com", ""),
+		Entry("required role account", address.Policy{RoleAccounts: address.Require}, "jane@example.com", "role account is required"),
+		Entry("custom role accounts", address.Policy{RoleAccounts: address.Forbid, RoleAccountNames: []string{"team"}}, "info@example.com", ""),
+	)
+
+	It("should validate the rules", func() {
+		Expect((&address.Policy{PlusAddressing: address.Forbid, RoleAccounts: address.Require}).Validate()).To(Succeed())
+		Expect((&address.Policy{RoleAccounts: "deny"}).Validate()).To(MatchError(`unknown role accounts rule "deny"`))
+		Expect((&address.Policy{MaxLength: -1}).Validate()).To(MatchError("maximum address length -1 is negative"))
+	})
+})
@@ -4,7 +4,10 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"sync/atomic"
 	"time"
 
 	"github.com/joho/godotenv"
@@ -12,12 +15,15 @@ import (
 	. "github.com/onsi/gomega"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	parhamv1 "github.com/parhamds/Email-Operator/api/v1"
+	"github.com/parhamds/Email-Operator/internal/address"
+	"github.com/parhamds/Email-Operator/internal/provider/capture"
 )
 
 type TestData struct {
@@ -94,6 +100,64 @@ func loadTestData() {
 	}
 }
 
+// createSenderConfig creates an EmailSenderConfig using the Capture provider
+// unless mutate changes it, and validates it. The config is deleted when the
+// spec ends, and the test email validating it is dropped from the capture
+// store.
+func createSenderConfig(name string, mutate func(*parhamv1.EmailSenderConfigSpec)) *parhamv1.EmailSenderConfig {
+	config := &parhamv1.EmailSenderConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: testData.Namespace},
+		Spec: parhamv1.EmailSenderConfigSpec{
+			Provider:    "Capture",
+			SenderEmail: "sender@example.com",
+		},
+	}
+	if mutate != nil {
+		mutate(&config.Spec)
+	}
+	Expect(k8sClient.Create(ctx, config)).To(Succeed())
+	DeferCleanup(func() {
+		Expect(k8sClient.Delete(ctx, config)).To(Succeed())
+	})
+
+	_, err := (&EmailSenderConfigReconciler{
+		Client: k8sClient,
+		Scheme: k8sClient.Scheme(),
+	}).Reconcile(ctx, reconcile.Request{
+		NamespacedName: types.NamespacedName{Name: config.Name, Namespace: config.Namespace},
+	})
+	Expect(err).NotTo(HaveOccurred())
+	capture.DefaultStore.Clear()
+	return config
+}
+
+// sendEmail creates an Email, reconciles it with r, or a default
+// EmailReconciler when r is nil, and returns it with its updated status.
+// The Email is deleted when the spec ends.
+func sendEmail(r *EmailReconciler, name string, spec parhamv1.EmailSpec) *parhamv1.Email {
+	if r == nil {
+		r = &EmailReconciler{
+			Client: k8sClient,
+			Scheme: k8sClient.Scheme(),
+		}
+	}
+	email := &parhamv1.Email{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: testData.Namespace},
+		Spec:       spec,
+	}
+	Expect(k8sClient.Create(ctx, email)).To(Succeed())
+	DeferCleanup(func() {
+		Expect(k8sClient.Delete(ctx, email)).To(Succeed())
+	})
+
+	_, err := r.Reconcile(ctx, reconcile.Request{
+		NamespacedName: types.NamespacedName{Name: email.Name, Namespace: email.Namespace},
+	})
+	Expect(err).NotTo(HaveOccurred())
+	Expect(k8sClient.Get(ctx, types.NamespacedName{Name: email.Name, Namespace: email.Namespace}, email)).To(Succeed())
+	return email
+}
+
 var _ = Describe("Email Controller", func() {
 	BeforeEach(func() {
 		ctx = context.Background()
@@ -512,4 +576,569 @@ var _ = Describe("Email Controller", func() {
 		}, time.Second*5, time.Millisecond*250).Should(ContainSubstring("unable to fetch EmailSenderConfig"))
 	})
 
+	It("should capture the email sent through the Capture senderconfig", func() {
+		By("Creating a Capture senderconfig")
+		captureConfig := createSenderConfig("test-senderconfig-capture", nil)
+
+		By("Creating an Email referencing it")
+		captureEmail := sendEmail(nil, "test-email-capture", parhamv1.EmailSpec{
+			SenderConfigRef: captureConfig.Name,
+			RecipientEmail:  "recipient@example.com",
+			Subject:         testData.EmailSubject,
+			Body:            testData.EmailBody,
+		})
+
+		By("Verifying the captured message matches the email")
+		Expect(captureEmail.Status.DeliveryStatus).To(Equal("Sent"))
+
+		msg, ok := capture.DefaultStore.Get(captureEmail.Status.MessageId)
+		Expect(ok).To(BeTrue())
+		Expect(msg.To).To(Equal([]string{"recipient@example.com"}))
+		Expect(msg.Subject).To(Equal(testData.EmailSubject))
+		Expect(msg.Body).To(Equal(testData.EmailBody))
+	})
+
+	It("should generate the plain text body of an HTML email", func() {
+		By("Creating a Capture senderconfig")
+		captureConfig := createSenderConfig("test-senderconfig-html", nil)
+
+		By("Creating an Email with only an HTML body")
+		htmlEmail := sendEmail(nil, "test-email-html", parhamv1.EmailSpec{
+			SenderConfigRef: captureConfig.Name,
+			RecipientEmail:  "recipient@example.com",
+			Subject:         testData.EmailSubject,
+			HTML:            "<p>Your invoice is <b>ready</b>.</p>",
+		})
+
+		By("Verifying both bodies were sent")
+		msg, ok := capture.DefaultStore.Get(htmlEmail.Status.MessageId)
+		Expect(ok).To(BeTrue())
+		Expect(msg.Body).To(Equal("Your invoice is ready."))
+		Expect(msg.HTML).To(Equal("<p>Your invoice is <b>ready</b>.</p>"))
+		Expect(msg.Raw).To(ContainSubstring("Content-Type: multipart/alternative"))
+	})
+
+	It("should send to the valid recipients and record the rejected ones", func() {
+		By("Creating a Capture senderconfig")
+		captureConfig := createSenderConfig("test-senderconfig-recipients", nil)
+
+		By("Creating an Email with To, Cc and Bcc lists")
+		listEmail := sendEmail(nil, "test-email-recipients", parhamv1.EmailSpec{
+			SenderConfigRef: captureConfig.Name,
+			To: []parhamv1.EmailRecipient{
+				{Name: "Jane", Email: "jane@example.com"},
+				{Email: "not-an-email"},
+			},
+			Cc:      []parhamv1.EmailRecipient{{Email: "cc@example.com"}},
+			Bcc:     []parhamv1.EmailRecipient{{Email: "bcc@example.com"}},
+			Subject: testData.EmailSubject,
+			Body:    testData.EmailBody,
+		})
+
+		By("Verifying the accepted and rejected recipients")
+		Expect(listEmail.Status.DeliveryStatus).To(Equal("Sent"))
+		Expect(listEmail.Status.AcceptedRecipients).To(Equal([]string{"jane@example.com", "cc@example.com", "bcc@example.com"}))
+		Expect(listEmail.Status.RejectedRecipients).To(Equal([]parhamv1.RejectedRecipient{
+			{Email: "not-an-email", Reason: "email must be a valid email address"},
+		}))
+
+		msg, ok := capture.DefaultStore.Get(listEmail.Status.MessageId)
+		Expect(ok).To(BeTrue())
+		Expect(msg.To).To(Equal([]string{"jane@example.com"}))
+		Expect(msg.Raw).To(ContainSubstring("To: \"Jane\" <jane@example.com>\r\n"))
+		Expect(msg.Raw).NotTo(ContainSubstring("bcc@example.com"))
+
+		By("Validating the recipients against the address policy")
+		policyEmail := sendEmail(&EmailReconciler{
+			Client: k8sClient,
+			Scheme: k8sClient.Scheme(),
+			AddressPolicy: address.Policy{
+				PlusAddressing: address.Forbid,
+				RoleAccounts:   address.Forbid,
+				SMTPUTF8:       true,
+			},
+		}, "test-email-address-policy", parhamv1.EmailSpec{
+			SenderConfigRef: captureConfig.Name,
+			To: []parhamv1.EmailRecipient{
+				{Email: "John Doe <John.Doe@Example.com>"},
+				{Email: "jürgen@bücher.de"},
+				{Email: "jane+news@example.com"},
+				{Email: "info@example.com"},
+			},
+			Subject: testData.EmailSubject,
+			Body:    testData.EmailBody,
+		})
+
+		Expect(policyEmail.Status.DeliveryStatus).To(Equal("Sent"))
+		Expect(policyEmail.Status.AcceptedRecipients).To(Equal([]string{"John.Doe@Example.com", "jürgen@xn--bcher-kva.de"}))
+		Expect(policyEmail.Status.RejectedRecipients).To(Equal([]parhamv1.RejectedRecipient{
+			{Email: "jane+news@example.com", Reason: "email must be a valid email address: plus addressing is not allowed"},
+			{Email: "info@example.com", Reason: "email must be a valid email address: role account is not allowed"},
+		}))
+
+		msg, ok = capture.DefaultStore.Get(policyEmail.Status.MessageId)
+		Expect(ok).To(BeTrue())
+		Expect(msg.Raw).To(ContainSubstring("\"John Doe\" <John.Doe@Example.com>"))
+	})
+
+	It("should fail over to the fallbacks of the senderconfig", func() {
+		By("Creating an invalid and a valid fallback and a primary senderconfig whose provider becomes unavailable")
+		var requests atomic.Int32
+		outage := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// The test email validating the senderconfig is accepted.
+			if requests.Add(1) > 1 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+			}
+		}))
+		defer outage.Close()
+
+		invalid := createSenderConfig("test-senderconfig-fallback-invalid", func(spec *parhamv1.EmailSenderConfigSpec) {
+			spec.Provider = "MailGunn"
+		})
+		fallback := createSenderConfig("test-senderconfig-fallback", nil)
+		primary := createSenderConfig("test-senderconfig-primary", func(spec *parhamv1.EmailSenderConfigSpec) {
+			spec.Provider = "Webhook"
+			spec.Webhook = &parhamv1.WebhookConfig{URL: outage.URL}
+			spec.Fallbacks = []string{"test-senderconfig-missing", invalid.Name, fallback.Name}
+		})
+
+		By("Sending an Email through the primary senderconfig")
+		failoverEmail := sendEmail(nil, "test-email-failover", parhamv1.EmailSpec{
+			SenderConfigRef: primary.Name,
+			RecipientEmail:  "recipient@example.com",
+			Subject:         testData.EmailSubject,
+			Body:            testData.EmailBody,
+		})
+
+		By("Verifying the valid fallback delivered the message")
+		Expect(failoverEmail.Status.DeliveryStatus).To(Equal("Sent"))
+		Expect(failoverEmail.Status.SenderConfig).To(Equal(fallback.Name))
+		Expect(failoverEmail.Status.Provider).To(Equal("Capture"))
+		Expect(capture.DefaultStore.List()).To(HaveLen(1))
+	})
+
+	It("should not fail over on permanent errors", func() {
+		By("Creating a fallback and a primary senderconfig whose body template fails for tagged messages")
+		gateway := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+		defer gateway.Close()
+
+		fallback := createSenderConfig("test-senderconfig-permanent-fallback", nil)
+		primary := createSenderConfig("test-senderconfig-permanent-primary", func(spec *parhamv1.EmailSenderConfigSpec) {
+			spec.Provider = "Webhook"
+			spec.Webhook = &parhamv1.WebhookConfig{
+				URL:          gateway.URL,
+				BodyTemplate: `{{if .Tags}}{{template "missing"}}{{end}}{}`,
+			}
+			spec.Fallbacks = []string{fallback.Name}
+		})
+
+		By("Sending a tagged Email through the primary senderconfig")
+		permanentEmail := sendEmail(nil, "test-email-permanent", parhamv1.EmailSpec{
+			SenderConfigRef: primary.Name,
+			RecipientEmail:  "recipient@example.com",
+			Subject:         testData.EmailSubject,
+			Body:            testData.EmailBody,
+			MessageOptions:  parhamv1.MessageOptions{Tags: []string{"billing"}},
+		})
+
+		By("Verifying the Email failed without trying the fallback")
+		Expect(permanentEmail.Status.DeliveryStatus).To(Equal("Failed"))
+		Expect(permanentEmail.Status.Error).To(ContainSubstring("unable to render body template"))
+		Expect(capture.DefaultStore.List()).To(BeEmpty())
+	})
+
+	It("should send through a valid member of the senderpool", func() {
+		By("Creating a valid and an invalid member senderconfig")
+		valid := createSenderConfig("test-senderconfig-pool-valid", nil)
+		invalid := createSenderConfig("test-senderconfig-pool-invalid", func(spec *parhamv1.EmailSenderConfigSpec) {
+			spec.Provider = "MailGunn"
+		})
+
+		pool := &parhamv1.EmailSenderPool{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-senderpool", Namespace: testData.Namespace},
+			Spec: parhamv1.EmailSenderPoolSpec{
+				Members: []parhamv1.EmailSenderPoolMember{
+					{SenderConfigRef: invalid.Name, Weight: ptr.To[int32](100)},
+					{SenderConfigRef: valid.Name},
+				},
+			},
+		}
+		Expect(k8sClient.Create(ctx, pool)).To(Succeed())
+		defer func() {
+			Expect(k8sClient.Delete(ctx, pool)).To(Succeed())
+		}()
+
+		By("Sending an Email through the senderpool")
+		poolEmail := sendEmail(nil, "test-email-pool", parhamv1.EmailSpec{
+			SenderPoolRef:  pool.Name,
+			RecipientEmail: "recipient@example.com",
+			Subject:        testData.EmailSubject,
+			Body:           testData.EmailBody,
+		})
+
+		By("Verifying the valid member delivered the message")
+		Expect(poolEmail.Status.DeliveryStatus).To(Equal("Sent"))
+		Expect(poolEmail.Status.SenderConfig).To(Equal(valid.Name))
+		Expect(capture.DefaultStore.List()).To(HaveLen(1))
+	})
+
+	It("should attach the ConfigMap and Secret keys referenced by the email", func() {
+		By("Creating a Capture senderconfig")
+		captureConfig := createSenderConfig("test-senderconfig-attachments", nil)
+
+		By("Creating the ConfigMap and Secret holding the attachments")
+		report := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-attachment-report", Namespace: testData.Namespace},
+			Data:       map[string]string{"report.csv": "name,total\njane,10\n"},
+		}
+		Expect(k8sClient.Create(ctx, report)).To(Succeed())
+		defer func() {
+			Expect(k8sClient.Delete(ctx, report)).To(Succeed())
+		}()
+		logo := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-attachment-logo", Namespace: testData.Namespace},
+			Data:       map[string][]byte{"logo.png": []byte("\x89PNG")},
+		}
+		Expect(k8sClient.Create(ctx, logo)).To(Succeed())
+		defer func() {
+			Expect(k8sClient.Delete(ctx, logo)).To(Succeed())
+		}()
+
+		attachments := []parhamv1.EmailAttachment{
+			{
+				Filename:        "report.csv",
+				ContentType:     "text/csv",
+				ConfigMapKeyRef: &corev1.ConfigMapKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: report.Name}, Key: "report.csv"},
+			},
+			{
+				Filename:     "logo.png",
+				ContentID:    "logo",
+				SecretKeyRef: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: logo.Name}, Key: "logo.png"},
+			},
+		}
+
+		By("Creating an Email with the attachments")
+		attachmentEmail := sendEmail(nil, "test-email-attachments", parhamv1.EmailSpec{
+			SenderConfigRef: captureConfig.Name,
+			RecipientEmail:  "recipient@example.com",
+			Subject:         testData.EmailSubject,
+			HTML:            `<p>Your report is attached.</p><img src="cid:logo">`,
+			Attachments:     attachments,
+		})
+
+		By("Verifying the attachments were sent")
+		Expect(attachmentEmail.Status.DeliveryStatus).To(Equal("Sent"))
+		msg, ok := capture.DefaultStore.Get(attachmentEmail.Status.MessageId)
+		Expect(ok).To(BeTrue())
+		Expect(msg.Raw).To(ContainSubstring("Content-Type: multipart/mixed"))
+		Expect(msg.Raw).To(ContainSubstring("Content-Disposition: attachment; filename=report.csv"))
+		Expect(msg.Raw).To(ContainSubstring("Content-Type: text/csv; name=report.csv"))
+		Expect(msg.Raw).To(ContainSubstring("Content-Type: image/png; name=logo.png"))
+		Expect(msg.Raw).To(ContainSubstring("Content-ID: <logo>"))
+
+		By("Creating an Email exceeding the attachment size limit")
+		largeEmail := sendEmail(&EmailReconciler{
+			Client:            k8sClient,
+			Scheme:            k8sClient.Scheme(),
+			MaxAttachmentSize: 16,
+		}, "test-email-attachments-large", parhamv1.EmailSpec{
+			SenderConfigRef: captureConfig.Name,
+			RecipientEmail:  "recipient@example.com",
+			Subject:         testData.EmailSubject,
+			Body:            testData.EmailBody,
+			Attachments:     attachments,
+		})
+
+		Expect(largeEmail.Status.DeliveryStatus).To(Equal("Failed"))
+		Expect(largeEmail.Status.Error).To(Equal("attachments exceed the maximum total size of 16 bytes"))
+	})
+
+	It("should render the email templates with values from ConfigMaps, Secrets and objects", func() {
+		By("Creating a Capture senderconfig")
+		captureConfig := createSenderConfig("test-senderconfig-templates", nil)
+
+		By("Creating the ConfigMap and Secret holding the template data")
+		release := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-template-release", Namespace: testData.Namespace},
+			Data:       map[string]string{"version": "1.4.2"},
+		}
+		Expect(k8sClient.Create(ctx, release)).To(Succeed())
+		defer func() {
+			Expect(k8sClient.Delete(ctx, release)).To(Succeed())
+		}()
+		token := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-template-token", Namespace: testData.Namespace},
+			Data:       map[string][]byte{"token": []byte("s3cr3t")},
+		}
+		Expect(k8sClient.Create(ctx, token)).To(Succeed())
+		defer func() {
+			Expect(k8sClient.Delete(ctx, token)).To(Succeed())
+		}()
+
+		By("Creating an Email with template data")
+		templateEmail := sendEmail(nil, "test-email-templates", parhamv1.EmailSpec{
+			SenderConfigRef: captureConfig.Name,
+			RecipientEmail:  "recipient@example.com",
+			Subject:         "Release {{ .version }} for {{ .team }}",
+			Body:            "Token: {{ .token }}, released from {{ .configMap }}.",
+			TemplateData: []parhamv1.EmailTemplateData{
+				{Name: "team", Value: "platform"},
+				{
+					Name:            "version",
+					ConfigMapKeyRef: &corev1.ConfigMapKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: release.Name}, Key: "version"},
+				},
+				{
+					Name:         "token",
+					SecretKeyRef: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: token.Name}, Key: "token"},
+				},
+				{
+					Name:      "configMap",
+					ObjectRef: &parhamv1.TemplateObjectReference{APIVersion: "v1", Kind: "ConfigMap", Name: release.Name, JSONPath: ".metadata.name"},
+				},
+			},
+		})
+
+		By("Verifying the rendered email was sent")
+		Expect(templateEmail.Status.DeliveryStatus).To(Equal("Sent"))
+		msg, ok := capture.DefaultStore.Get(templateEmail.Status.MessageId)
+		Expect(ok).To(BeTrue())
+		Expect(msg.Subject).To(Equal("Release 1.4.2 for platform"))
+		Expect(msg.Body).To(Equal("Token: s3cr3t, released from test-template-release."))
+
+		By("Creating an Email with a template error")
+		brokenEmail := sendEmail(nil, "test-email-templates-broken", parhamv1.EmailSpec{
+			SenderConfigRef: captureConfig.Name,
+			RecipientEmail:  "recipient@example.com",
+			Subject:         "Release {{ .missing }}",
+			Body:            testData.EmailBody,
+			TemplateData:    []parhamv1.EmailTemplateData{{Name: "team", Value: "platform"}},
+		})
+
+		Expect(brokenEmail.Status.DeliveryStatus).To(Equal("Failed"))
+		Expect(brokenEmail.Status.Error).To(HavePrefix("unable to render template: "))
+		Expect(capture.DefaultStore.List()).To(HaveLen(1))
+	})
+
+	It("should render the email from an EmailTemplate and a ClusterEmailTemplate", func() {
+		By("Creating a Capture senderconfig")
+		captureConfig := createSenderConfig("test-senderconfig-emailtemplate", nil)
+
+		By("Creating the templates")
+		welcome := &parhamv1.EmailTemplate{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-template-welcome", Namespace: testData.Namespace},
+			Spec: parhamv1.EmailTemplateSpec{
+				Subject: "Welcome, {{ .name }}",
+				Text:    "Hello {{ .name }}, welcome to {{ .team }}.",
+				Variables: []parhamv1.EmailTemplateVariable{
+					{Name: "name", Required: true},
+					{Name: "team", Default: "the platform team"},
+				},
+			},
+		}
+		Expect(k8sClient.Create(ctx, welcome)).To(Succeed())
+		defer func() {
+			Expect(k8sClient.Delete(ctx, welcome)).To(Succeed())
+		}()
+		expiry := &parhamv1.ClusterEmailTemplate{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-template-expiry"},
+			Spec: parhamv1.EmailTemplateSpec{
+				Subject: "Certificate {{ .certificate }} expires soon",
+				HTML:    "<p>The certificate <b>{{ .certificate }}</b> expires soon.</p>",
+			},
+		}
+		Expect(k8sClient.Create(ctx, expiry)).To(Succeed())
+		defer func() {
+			Expect(k8sClient.Delete(ctx, expiry)).To(Succeed())
+		}()
+
+		By("Sending an Email from the EmailTemplate with a default variable")
+		welcomeEmail := sendEmail(nil, "test-email-emailtemplate", parhamv1.EmailSpec{
+			SenderConfigRef: captureConfig.Name,
+			RecipientEmail:  "recipient@example.com",
+			TemplateRef:     &parhamv1.EmailTemplateReference{Name: welcome.Name},
+			TemplateData:    []parhamv1.EmailTemplateData{{Name: "name", Value: "Jane"}},
+		})
+		Expect(welcomeEmail.Status.DeliveryStatus).To(Equal("Sent"))
+		msg, ok := capture.DefaultStore.Get(welcomeEmail.Status.MessageId)
+		Expect(ok).To(BeTrue())
+		Expect(msg.Subject).To(Equal("Welcome, Jane"))
+		Expect(msg.Body).To(Equal("Hello Jane, welcome to the platform team."))
+
+		By("Sending an Email from the ClusterEmailTemplate")
+		expiryEmail := sendEmail(nil, "test-email-clusteremailtemplate", parhamv1.EmailSpec{
+			SenderConfigRef: captureConfig.Name,
+			RecipientEmail:  "recipient@example.com",
+			TemplateRef:     &parhamv1.EmailTemplateReference{Kind: "ClusterEmailTemplate", Name: expiry.Name},
+			TemplateData:    []parhamv1.EmailTemplateData{{Name: "certificate", Value: "web-tls"}},
+		})
+		Expect(expiryEmail.Status.DeliveryStatus).To(Equal("Sent"))
+		msg, ok = capture.DefaultStore.Get(expiryEmail.Status.MessageId)
+		Expect(ok).To(BeTrue())
+		Expect(msg.Subject).To(Equal("Certificate web-tls expires soon"))
+		Expect(msg.HTML).To(Equal("<p>The certificate <b>web-tls</b> expires soon.</p>"))
+
+		By("Reporting missing required variables")
+		missingEmail := sendEmail(nil, "test-email-emailtemplate-missing", parhamv1.EmailSpec{
+			SenderConfigRef: captureConfig.Name,
+			RecipientEmail:  "recipient@example.com",
+			TemplateRef:     &parhamv1.EmailTemplateReference{Name: welcome.Name},
+		})
+		Expect(missingEmail.Status.DeliveryStatus).To(Equal("Failed"))
+		Expect(missingEmail.Status.Error).To(Equal("missing required template variables: name"))
+
+		By("Reporting a missing template")
+		unknownEmail := sendEmail(nil, "test-email-emailtemplate-unknown", parhamv1.EmailSpec{
+			SenderConfigRef: captureConfig.Name,
+			RecipientEmail:  "recipient@example.com",
+			TemplateRef:     &parhamv1.EmailTemplateReference{Name: "unknown"},
+		})
+		Expect(unknownEmail.Status.DeliveryStatus).To(Equal("Failed"))
+		Expect(unknownEmail.Status.Error).To(HavePrefix("unable to fetch EmailTemplate unknown: "))
+		Expect(capture.DefaultStore.List()).To(HaveLen(2))
+	})
+
+	It("should render a Markdown body in a layout", func() {
+		By("Creating a Capture senderconfig")
+		captureConfig := createSenderConfig("test-senderconfig-markdown", nil)
+
+		By("Creating an Email with a Markdown body")
+		markdownEmail := sendEmail(nil, "test-email-markdown", parhamv1.EmailSpec{
+			SenderConfigRef: captureConfig.Name,
+			RecipientEmail:  "recipient@example.com",
+			Subject:         testData.EmailSubject,
+			Body:            "Deployed **{{ .version }}**, see the [release notes](https://example.com/notes).",
+			Format:          parhamv1.FormatMarkdown,
+			Layout:          "basic",
+			TemplateData:    []parhamv1.EmailTemplateData{{Name: "version", Value: "1.4.2"}},
+		})
+
+		By("Verifying both bodies were rendered")
+		Expect(markdownEmail.Status.DeliveryStatus).To(Equal("Sent"))
+		msg, ok := capture.DefaultStore.Get(markdownEmail.Status.MessageId)
+		Expect(ok).To(BeTrue())
+		Expect(msg.Body).To(Equal("Deployed 1.4.2, see the release notes (https://example.com/notes)."))
+		Expect(msg.HTML).To(HavePrefix("<!DOCTYPE html>"))
+		Expect(msg.HTML).To(ContainSubstring(`<p>Deployed <strong>1.4.2</strong>, see the <a href="https://example.com/notes">release notes</a>.</p>`))
+	})
+
+	It("should merge the message options of the senderconfig and the email", func() {
+		By("Creating a Capture senderconfig with message options")
+		captureConfig := createSenderConfig("test-senderconfig-options", func(spec *parhamv1.EmailSenderConfigSpec) {
+			spec.MessageOptions = parhamv1.MessageOptions{
+				SenderName: "Example",
+				ReplyTo:    "support@example.com",
+				Headers:    []parhamv1.EmailHeader{{Name: "X-Campaign", Value: "default"}},
+				Tags:       []string{"operator"},
+				Metadata:   map[string]string{"team": "platform"},
+			}
+		})
+
+		By("Creating an Email overriding some of the options")
+		optionsEmail := sendEmail(nil, "test-email-options", parhamv1.EmailSpec{
+			SenderConfigRef: captureConfig.Name,
+			RecipientEmail:  "recipient@example.com",
+			Subject:         testData.EmailSubject,
+			Body:            testData.EmailBody,
+			MessageOptions: parhamv1.MessageOptions{
+				SenderName: "Billing",
+				Headers:    []parhamv1.EmailHeader{{Name: "x-campaign", Value: "spring"}},
+				Tags:       []string{"billing", "operator"},
+				Metadata:   map[string]string{"customer": "42"},
+			},
+		})
+
+		By("Verifying the merged options were sent")
+		Expect(optionsEmail.Status.DeliveryStatus).To(Equal("Sent"))
+		msg, ok := capture.DefaultStore.Get(optionsEmail.Status.MessageId)
+		Expect(ok).To(BeTrue())
+		Expect(msg.From).To(Equal(`"Billing" <sender@example.com>`))
+		Expect(msg.ReplyTo).To(Equal("support@example.com"))
+		Expect(msg.Tags).To(Equal([]string{"operator", "billing"}))
+		Expect(msg.Metadata).To(Equal(map[string]string{"team": "platform", "customer": "42"}))
+		Expect(msg.Raw).To(ContainSubstring("x-campaign: spring\r\n"))
+		Expect(msg.Raw).NotTo(ContainSubstring("X-Campaign: default"))
+
+		By("Failing an Email setting a protected header")
+		protectedEmail := sendEmail(nil, "test-email-protected-header", parhamv1.EmailSpec{
+			SenderConfigRef: captureConfig.Name,
+			RecipientEmail:  "recipient@example.com",
+			Subject:         testData.EmailSubject,
+			Body:            testData.EmailBody,
+			MessageOptions: parhamv1.MessageOptions{
+				Headers: []parhamv1.EmailHeader{{Name: "Return-Path", Value: "bounce@example.com"}},
+			},
+		})
+		Expect(protectedEmail.Status.DeliveryStatus).To(Equal("Failed"))
+		Expect(protectedEmail.Status.Error).To(Equal("invalid message options: header Return-Path cannot be set"))
+
+		By("Failing an Email with tags through a provider without tags")
+		fileConfig := createSenderConfig("test-senderconfig-options-file", func(spec *parhamv1.EmailSenderConfigSpec) {
+			spec.Provider = "File"
+			spec.File = &parhamv1.FileConfig{Directory: GinkgoT().TempDir(), Format: "eml"}
+		})
+		taggedEmail := sendEmail(nil, "test-email-options-tags", parhamv1.EmailSpec{
+			SenderConfigRef: fileConfig.Name,
+			RecipientEmail:  "recipient@example.com",
+			Subject:         testData.EmailSubject,
+			Body:            testData.EmailBody,
+			MessageOptions:  parhamv1.MessageOptions{Tags: []string{"billing"}},
+		})
+		Expect(taggedEmail.Status.DeliveryStatus).To(Equal("Failed"))
+		Expect(taggedEmail.Status.Error).To(ContainSubstring("provider File does not support tags"))
+	})
+
+	It("should send provider templates only through providers storing templates", func() {
+		By("Creating a Capture and a Webhook senderconfig")
+		gateway := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+		defer gateway.Close()
+
+		captureConfig := createSenderConfig("test-senderconfig-stored", nil)
+		webhookConfig := createSenderConfig("test-senderconfig-no-stored", func(spec *parhamv1.EmailSenderConfigSpec) {
+			spec.Provider = "Webhook"
+			spec.Webhook = &parhamv1.WebhookConfig{URL: gateway.URL}
+		})
+
+		template := &parhamv1.ProviderTemplate{
+			ID:        "welcome",
+			Variables: map[string]string{"name": "Jane"},
+		}
+
+		By("Sending the template through the Capture provider")
+		storedEmail := sendEmail(nil, "test-email-stored", parhamv1.EmailSpec{
+			SenderConfigRef:  captureConfig.Name,
+			RecipientEmail:   "recipient@example.com",
+			ProviderTemplate: template,
+		})
+		Expect(storedEmail.Status.DeliveryStatus).To(Equal("Sent"))
+		msg, ok := capture.DefaultStore.Get(storedEmail.Status.MessageId)
+		Expect(ok).To(BeTrue())
+		Expect(msg.Template).To(Equal("welcome"))
+		Expect(msg.TemplateVariables).To(Equal(map[string]string{"name": "Jane"}))
+
+		By("Failing the template through the Webhook provider")
+		unsupportedEmail := sendEmail(nil, "test-email-no-stored", parhamv1.EmailSpec{
+			SenderConfigRef:  webhookConfig.Name,
+			RecipientEmail:   "recipient@example.com",
+			ProviderTemplate: template,
+		})
+		Expect(unsupportedEmail.Status.DeliveryStatus).To(Equal("Failed"))
+		Expect(unsupportedEmail.Status.Error).To(ContainSubstring("provider Webhook does not support stored templates"))
+	})
+
+	It("should pick senderpool members in proportion to their weight", func() {
+		members := []parhamv1.EmailSenderPoolMember{
+			{SenderConfigRef: "a"},
+			{SenderConfigRef: "b", Weight: ptr.To[int32](3)},
+			{SenderConfigRef: "drained", Weight: ptr.To[int32](0)},
+		}
+		counts := map[string]int{}
+		for i := int64(0); i < 4; i++ {
+			counts[pickWeighted(members, func(n int64) int64 {
+				Expect(n).To(Equal(int64(4)))
+				return i
+			})]++
+		}
+		Expect(counts).To(Equal(map[string]int{"a": 1, "b": 3}))
+	})
+
 })
@@ -4,24 +4,41 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"math/rand/v2"
+	"net/mail"
+	"strings"
 
 	"k8s.io/apimachinery/pkg/runtime"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	parhamv1 "github.com/parhamds/Email-Operator/api/v1"
+	"github.com/parhamds/Email-Operator/internal/address"
+	"github.com/parhamds/Email-Operator/internal/provider"
 )
 
 // EmailReconciler reconciles an Email object
 type EmailReconciler struct {
 	client.Client
 	Scheme *runtime.Scheme
+	// Transport holds the default proxy and TLS settings of the providers.
+	Transport provider.TransportOptions
+	// MaxAttachmentSize bounds the total size of the attachments of an Email
+	// in bytes, DefaultMaxAttachmentSize is used when it is zero.
+	MaxAttachmentSize int64
+	// AddressPolicy decides which recipient addresses are accepted.
+	AddressPolicy address.Policy
 }
 
 // +kubebuilder:rbac:groups=parham.my.domain,resources=emails,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=parham.my.domain,resources=emails/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=parham.my.domain,resources=emails/finalizers,verbs=update
+// +kubebuilder:rbac:groups=parham.my.domain,resources=emailsenderpools,verbs=get;list;watch
+// +kubebuilder:rbac:groups=parham.my.domain,resources=emailtemplates,verbs=get;list;watch
+// +kubebuilder:rbac:groups=parham.my.domain,resources=clusteremailtemplates,verbs=get;list;watch
 
 func (r *EmailReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	log := log.FromContext(ctx)
@@ -44,35 +61,196 @@ func (r *EmailReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl
 		return ctrl.Result{}, nil
 	}
 
+	// Render the subject and bodies before any send attempt
+	content, err := renderContent(ctx, r.Client, &email)
+	if err != nil {
+		log.Info("Unable to render Email", "error", err.Error())
+		updateEmailStatus(r, ctx, &email, "Failed", err.Error())
+		return ctrl.Result{}, nil
+	}
+
+	// Pick the EmailSenderConfig from the pool referenced in the Email
+	senderConfigRef := email.Spec.SenderConfigRef
+	if email.Spec.SenderPoolRef != "" {
+		senderConfigRef, err = r.pickPoolMember(ctx, req.Namespace, email.Spec.SenderPoolRef)
+		if err != nil {
+			log.Info("Unable to pick EmailSenderConfig from EmailSenderPool", "error", err.Error())
+			updateEmailStatus(r, ctx, &email, "Failed", err.Error())
+			return ctrl.Result{}, nil
+		}
+	}
+
 	// Fetch the EmailSenderConfig referenced in the Email
 	var senderConfig parhamv1.EmailSenderConfig
-	if err := r.Get(ctx, client.ObjectKey{Name: email.Spec.SenderConfigRef, Namespace: req.Namespace}, &senderConfig); err != nil {
+	if err := r.Get(ctx, client.ObjectKey{Name: senderConfigRef, Namespace: req.Namespace}, &senderConfig); err != nil {
 		log.Info("Unable to fetch EmailSenderConfig", "error", err.Error())
 		updateEmailStatus(r, ctx, &email, "Failed", fmt.Sprintf("unable to fetch EmailSenderConfig: %v", err))
 		return ctrl.Result{}, nil
 	}
 
-	if !senderConfig.Status.Valid {
-		err := errors.New("the emailsenderconfig is not valid")
-		log.Error(err, "failed to send email")
-		updateEmailStatus(r, ctx, &email, "Failed", fmt.Sprintf("failed to send email: %v", err))
+	// Validate the recipients, sending to the valid ones only
+	to := email.Spec.To
+	if email.Spec.RecipientEmail != "" {
+		to = append([]parhamv1.EmailRecipient{{Email: email.Spec.RecipientEmail}}, to...)
+	}
+	msg := provider.Message{Subject: content.Subject, Body: content.Text, HTML: content.HTML}
+	if t := email.Spec.ProviderTemplate; t != nil {
+		msg.Template = &provider.StoredTemplate{ID: t.ID, Variables: t.Variables}
+	}
+	var rejected, rejectedCc, rejectedBcc []parhamv1.RejectedRecipient
+	msg.To, rejected = splitRecipients(&r.AddressPolicy, to)
+	msg.Cc, rejectedCc = splitRecipients(&r.AddressPolicy, email.Spec.Cc)
+	msg.Bcc, rejectedBcc = splitRecipients(&r.AddressPolicy, email.Spec.Bcc)
+	email.Status.RejectedRecipients = append(append(rejected, rejectedCc...), rejectedBcc...)
+	if len(msg.Recipients()) == 0 {
+		log.Error(errInvalidRecipient, "failed to send email")
+		updateEmailStatus(r, ctx, &email, "Failed", fmt.Sprintf("failed to send email: %v", errInvalidRecipient))
+		return ctrl.Result{}, nil
+	}
+
+	if err := validateMessageOptions(email.Spec.MessageOptions); err != nil {
+		log.Info("Invalid message options", "error", err.Error())
+		updateEmailStatus(r, ctx, &email, "Failed", fmt.Sprintf("invalid message options: %v", err))
 		return ctrl.Result{}, nil
 	}
 
-	// Send email
-	id, err := sendEmailMessage(ctx, r.Client, &senderConfig, email.Spec.RecipientEmail, email.Spec.Subject, email.Spec.Body)
+	// Read the attachments from their ConfigMaps and Secrets
+	maxSize := r.MaxAttachmentSize
+	if maxSize <= 0 {
+		maxSize = DefaultMaxAttachmentSize
+	}
+	attachments, err := resolveAttachments(ctx, r.Client, req.Namespace, email.Spec.Attachments, maxSize)
 	if err != nil {
-		log.Error(err, "failed to send email")
-		updateEmailStatus(r, ctx, &email, "Failed", fmt.Sprintf("failed to send email: %v", err))
+		log.Info("Unable to read attachments", "error", err.Error())
+		updateEmailStatus(r, ctx, &email, "Failed", err.Error())
 		return ctrl.Result{}, nil
 	}
-	email.Status.MessageId = id
+	msg.Attachments = attachments
+
+	// Send email, failing over to the fallbacks of the sender config unless
+	// the error is permanent
+	var failures []string
+	for i, name := range append([]string{senderConfig.Name}, senderConfig.Spec.Fallbacks...) {
+		current := &senderConfig
+		if i > 0 {
+			current = &parhamv1.EmailSenderConfig{}
+			if err := r.Get(ctx, client.ObjectKey{Name: name, Namespace: req.Namespace}, current); err != nil {
+				failures = append(failures, fmt.Sprintf("%s: unable to fetch EmailSenderConfig: %v", name, err))
+				continue
+			}
+		}
 
-	updateEmailStatus(r, ctx, &email, "Sent", "")
+		if !current.Status.Valid {
+			err := errors.New("the emailsenderconfig is not valid")
+			log.Error(err, "failed to send email", "EmailSenderConfig", name)
+			failures = append(failures, fmt.Sprintf("%s: %v", name, err))
+			continue
+		}
 
+		res, err := sendEmailMessage(ctx, r.Client, r.Transport, current, email.Spec.MessageOptions, msg)
+		if err != nil {
+			log.Error(err, "failed to send email", "EmailSenderConfig", name)
+			failures = append(failures, fmt.Sprintf("%s: %v", name, err))
+			if provider.IsPermanent(err) {
+				break
+			}
+			continue
+		}
+
+		email.Status.MessageId = res.MessageID
+		email.Status.ThreadId = res.ThreadID
+		email.Status.SenderConfig = name
+		email.Status.Provider = current.Spec.Provider
+		email.Status.AcceptedRecipients = acceptedRecipients(msg.Recipients(), res.Rejected)
+		for _, rcpt := range res.Rejected {
+			email.Status.RejectedRecipients = append(email.Status.RejectedRecipients, parhamv1.RejectedRecipient{Email: rcpt.Address, Reason: rcpt.Reason})
+		}
+		updateEmailStatus(r, ctx, &email, "Sent", "")
+		return ctrl.Result{}, nil
+	}
+
+	updateEmailStatus(r, ctx, &email, "Failed", fmt.Sprintf("failed to send email: %s", failureMessage(failures)))
 	return ctrl.Result{}, nil
 }
 
+// acceptedRecipients returns the addresses of recipients the provider did not
+// reject.
+func acceptedRecipients(recipients []mail.Address, rejected []provider.RejectedRecipient) []string {
+	refused := map[string]bool{}
+	for _, rcpt := range rejected {
+		refused[rcpt.Address] = true
+	}
+	var accepted []string
+	for _, rcpt := range recipients {
+		if !refused[rcpt.Address] {
+			accepted = append(accepted, rcpt.Address)
+		}
+	}
+	return accepted
+}
+
+// failureMessage joins the errors of every attempted sender config. A single
+// failure is reported without the sender config name.
+func failureMessage(failures []string) string {
+	if len(failures) == 1 {
+		_, msg, _ := strings.Cut(failures[0], ": ")
+		return msg
+	}
+	return strings.Join(failures, "; ")
+}
+
+// pickPoolMember returns a valid member of the pool, picked at random in
+// proportion to its weight.
+func (r *EmailReconciler) pickPoolMember(ctx context.Context, namespace, poolName string) (string, error) {
+	var pool parhamv1.EmailSenderPool
+	if err := r.Get(ctx, client.ObjectKey{Name: poolName, Namespace: namespace}, &pool); err != nil {
+		return "", fmt.Errorf("unable to fetch EmailSenderPool: %v", err)
+	}
+
+	var valid []parhamv1.EmailSenderPoolMember
+	for _, member := range pool.Spec.Members {
+		if memberWeight(member) <= 0 {
+			continue
+		}
+		var senderConfig parhamv1.EmailSenderConfig
+		if err := r.Get(ctx, client.ObjectKey{Name: member.SenderConfigRef, Namespace: namespace}, &senderConfig); err != nil {
+			continue
+		}
+		if senderConfig.Status.Valid {
+			valid = append(valid, member)
+		}
+	}
+	if len(valid) == 0 {
+		return "", fmt.Errorf("the emailsenderpool %s has no valid members", poolName)
+	}
+	return pickWeighted(valid, rand.Int64N), nil
+}
+
+// pickWeighted picks a member with a probability proportional to its weight,
+// randN returns a number in [0, n).
+func pickWeighted(members []parhamv1.EmailSenderPoolMember, randN func(n int64) int64) string {
+	var total int64
+	for _, member := range members {
+		total += memberWeight(member)
+	}
+	n := randN(total)
+	for _, member := range members {
+		n -= memberWeight(member)
+		if n < 0 {
+			return member.SenderConfigRef
+		}
+	}
+	return members[len(members)-1].SenderConfigRef
+}
+
+// memberWeight returns the weight of member, 1 when it is not set.
+func memberWeight(member parhamv1.EmailSenderPoolMember) int64 {
+	if member.Weight == nil {
+		return 1
+	}
+	return int64(*member.Weight)
+}
+
 func updateEmailStatus(r *EmailReconciler, ctx context.Context, email *parhamv1.Email, status, errMsg string) {
 	if email.Status.DeliveryStatus == status && email.Status.Error == errMsg {
 		return
@@ -85,9 +263,66 @@ func updateEmailStatus(r *EmailReconciler, ctx context.Context, email *parhamv1.
 	}
 }
 
+const (
+	clusterEmailTemplateKind = "ClusterEmailTemplate"
+	// templateRefField indexes Emails by the template they reference.
+	templateRefField = ".spec.templateRef"
+)
+
+// templateKey identifies a template in the templateRefField index, namespace
+// is empty for ClusterEmailTemplates.
+func templateKey(kind, namespace, name string) string {
+	if kind == clusterEmailTemplateKind {
+		namespace = ""
+	}
+	return kind + "/" + namespace + "/" + name
+}
+
+// emailsForTemplate requeues the Emails referencing a changed template that
+// have not been sent yet.
+func (r *EmailReconciler) emailsForTemplate(ctx context.Context, obj client.Object) []reconcile.Request {
+	kind := "EmailTemplate"
+	opts := []client.ListOption{client.InNamespace(obj.GetNamespace())}
+	if _, ok := obj.(*parhamv1.ClusterEmailTemplate); ok {
+		kind = clusterEmailTemplateKind
+		opts = nil
+	}
+	opts = append(opts, client.MatchingFields{templateRefField: templateKey(kind, obj.GetNamespace(), obj.GetName())})
+
+	var emails parhamv1.EmailList
+	if err := r.List(ctx, &emails, opts...); err != nil {
+		log.FromContext(ctx).Error(err, "unable to list Emails referencing template", "template", obj.GetName())
+		return nil
+	}
+	var requests []reconcile.Request
+	for _, email := range emails.Items {
+		if email.Status.DeliveryStatus == "Sent" {
+			continue
+		}
+		requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&email)})
+	}
+	return requests
+}
+
 // SetupWithManager sets up the controller with the Manager.
 func (r *EmailReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &parhamv1.Email{}, templateRefField, func(obj client.Object) []string {
+		ref := obj.(*parhamv1.Email).Spec.TemplateRef
+		if ref == nil {
+			return nil
+		}
+		kind := ref.Kind
+		if kind == "" {
+			kind = "EmailTemplate"
+		}
+		return []string{templateKey(kind, obj.GetNamespace(), ref.Name)}
+	}); err != nil {
+		return err
+	}
+
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&parhamv1.Email{}).
+		Watches(&parhamv1.EmailTemplate{}, handler.EnqueueRequestsFromMapFunc(r.emailsForTemplate)).
+		Watches(&parhamv1.ClusterEmailTemplate{}, handler.EnqueueRequestsFromMapFunc(r.emailsForTemplate)).
 		Complete(r)
 }
@@ -2,6 +2,7 @@ package controller
 
 import (
 	"context"
+	"net/mail"
 
 	"k8s.io/apimachinery/pkg/runtime"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -9,17 +10,21 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/log"
 
 	parhamv1 "github.com/parhamds/Email-Operator/api/v1"
+	"github.com/parhamds/Email-Operator/internal/provider"
 )
 
 type EmailSenderConfigReconciler struct {
 	client.Client
 	Scheme *runtime.Scheme
+	// Transport holds the default proxy and TLS settings of the providers.
+	Transport provider.TransportOptions
 }
 
 // +kubebuilder:rbac:groups=parham.my.domain,resources=emailsenderconfigs,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=parham.my.domain,resources=emailsenderconfigs/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=parham.my.domain,resources=emailsenderconfigs/finalizers,verbs=update
 // +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch
 
 func (r *EmailSenderConfigReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	log := log.FromContext(ctx)
@@ -35,7 +40,11 @@ func (r *EmailSenderConfigReconciler) Reconcile(ctx context.Context, req ctrl.Re
 		return ctrl.Result{}, nil
 	}
 	// Send test email to verify the configuration
-	_, err := sendEmailMessage(ctx, r.Client, &senderConfig, "parham.dskn@gmail.com", "Test Email", "This is a test email to verify the EmailSenderConfig.")
+	_, err := sendEmailMessage(ctx, r.Client, r.Transport, &senderConfig, parhamv1.MessageOptions{}, provider.Message{
+		To:      []mail.Address{{Address: "parham.dskn@gmail.com"}},
+		Subject: "Test Email",
+		Body:    "This is a test email to verify the EmailSenderConfig.",
+	})
 	if err != nil {
 		updateEmailSenderConfigStatus(r, ctx, &senderConfig, false)
 		log.Error(err, "failed to send test email", "EmailSenderConfig", senderConfig.Name)
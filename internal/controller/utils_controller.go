@@ -4,131 +4,480 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"net/http"
-	"regexp"
+	"maps"
+	"mime"
+	"net/mail"
+	"path/filepath"
+	"slices"
 	"strings"
-	"time"
 
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/util/jsonpath"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
-	"github.com/mailersend/mailersend-go"
-	mailgun "github.com/mailgun/mailgun-go/v4"
 	parhamv1 "github.com/parhamds/Email-Operator/api/v1"
+	"github.com/parhamds/Email-Operator/internal/address"
+	"github.com/parhamds/Email-Operator/internal/markdown"
+	"github.com/parhamds/Email-Operator/internal/message"
+	"github.com/parhamds/Email-Operator/internal/provider"
+	_ "github.com/parhamds/Email-Operator/internal/provider/capture"
+	_ "github.com/parhamds/Email-Operator/internal/provider/file"
+	_ "github.com/parhamds/Email-Operator/internal/provider/gmail"
+	_ "github.com/parhamds/Email-Operator/internal/provider/graph"
+	_ "github.com/parhamds/Email-Operator/internal/provider/maildir"
+	_ "github.com/parhamds/Email-Operator/internal/provider/mailersend"
+	_ "github.com/parhamds/Email-Operator/internal/provider/mailgun"
+	_ "github.com/parhamds/Email-Operator/internal/provider/postmark"
+	_ "github.com/parhamds/Email-Operator/internal/provider/sendgrid"
+	_ "github.com/parhamds/Email-Operator/internal/provider/ses"
+	_ "github.com/parhamds/Email-Operator/internal/provider/smtp"
+	_ "github.com/parhamds/Email-Operator/internal/provider/webhook"
+	"github.com/parhamds/Email-Operator/internal/render"
 )
 
-func isValidEmail(email string) bool {
-	re := regexp.MustCompile(`^[a-z0-9._%+\-]+@[a-z0-9.\-]+\.[a-z]{2,}$`)
-	return re.MatchString(email)
+// errInvalidRecipient is returned for recipients no provider could deliver to.
+var errInvalidRecipient = address.ErrInvalid
+
+// splitRecipients validates every recipient of list individually against
+// policy and returns the valid addresses and the rejected recipients. The
+// display name of an address is used for recipients without a name.
+func splitRecipients(policy *address.Policy, list []parhamv1.EmailRecipient) ([]mail.Address, []parhamv1.RejectedRecipient) {
+	var (
+		valid    []mail.Address
+		rejected []parhamv1.RejectedRecipient
+	)
+	for _, recipient := range list {
+		addr, err := policy.Parse(recipient.Email)
+		if err != nil {
+			rejected = append(rejected, parhamv1.RejectedRecipient{Email: recipient.Email, Reason: err.Error()})
+			continue
+		}
+		if recipient.Name != "" {
+			addr.Name = recipient.Name
+		}
+		valid = append(valid, *addr)
+	}
+	return valid, rejected
 }
 
-func sendEmailMessage(ctx context.Context, c client.Client, senderConfig *parhamv1.EmailSenderConfig, recipientEmail, subject, body string) (string, error) {
-	var id string
-	if !isValidEmail(recipientEmail) {
-		return "", fmt.Errorf("email must be a valid email address")
+// sendEmailMessage sends msg from the sender of senderConfig with options
+// merged over the message options of senderConfig. A plain text body is
+// generated when msg only has an HTML body.
+func sendEmailMessage(ctx context.Context, c client.Client, transport provider.TransportOptions, senderConfig *parhamv1.EmailSenderConfig, options parhamv1.MessageOptions, msg provider.Message) (*provider.Result, error) {
+	p, err := newProvider(ctx, c, transport, senderConfig)
+	if err != nil {
+		return nil, err
 	}
 
-	apiToken, err := getApiTokenFromSecret(ctx, c, senderConfig.Namespace, senderConfig.Spec.ApiTokenSecretRef, "apiToken")
+	if len(msg.Attachments) > 0 && !p.Capabilities().Attachments {
+		return nil, fmt.Errorf("provider %s does not support attachments", senderConfig.Spec.Provider)
+	}
+	if msg.Template != nil && !p.Capabilities().StoredTemplates {
+		return nil, fmt.Errorf("provider %s does not support stored templates", senderConfig.Spec.Provider)
+	}
+
+	options = mergeMessageOptions(senderConfig.Spec.MessageOptions, options)
+	if len(options.Headers) > 0 && !p.Capabilities().Headers {
+		return nil, fmt.Errorf("provider %s does not support custom headers", senderConfig.Spec.Provider)
+	}
+	if len(options.Tags) > 0 && !p.Capabilities().Tags {
+		return nil, fmt.Errorf("provider %s does not support tags", senderConfig.Spec.Provider)
+	}
+	if limit := p.Capabilities().MaxTags; limit > 0 && len(options.Tags) > limit {
+		return nil, fmt.Errorf("provider %s supports at most %d tags", senderConfig.Spec.Provider, limit)
+	}
+	if len(options.Metadata) > 0 && !p.Capabilities().Metadata {
+		return nil, fmt.Errorf("provider %s does not support metadata", senderConfig.Spec.Provider)
+	}
+	applyMessageOptions(&msg, senderConfig.Spec.SenderEmail, options)
+	if msg.Body == "" && msg.HTML != "" {
+		msg.Body = provider.HTMLToText(msg.HTML)
+	}
+	res, err := p.Send(ctx, &msg)
 	if err != nil {
-		return "", fmt.Errorf("failed to retrieve API token: %v", err)
+		return nil, fmt.Errorf("failed to send email: %w", err)
 	}
+	return res, nil
+}
 
-	if senderConfig.Spec.Provider == "MailerSend" {
-		id, err = sendEmailMessageMailerSend(ctx, senderConfig, recipientEmail, subject, body, apiToken)
-		if err != nil {
-			return "", fmt.Errorf("failed to send email: %v", err)
-		}
-	} else {
-		id, err = sendEmailMessageMailgun(ctx, senderConfig, recipientEmail, subject, body, apiToken)
-		if err != nil {
-			return "", fmt.Errorf("failed to send email: %v", err)
+// mergeMessageOptions returns the options of an Email merged over those of
+// its sender config. Headers replace the config headers of the same name,
+// tags are added to the config tags and metadata keys replace config keys.
+func mergeMessageOptions(config, email parhamv1.MessageOptions) parhamv1.MessageOptions {
+	merged := parhamv1.MessageOptions{
+		SenderName: config.SenderName,
+		ReplyTo:    config.ReplyTo,
+	}
+	if email.SenderName != "" {
+		merged.SenderName = email.SenderName
+	}
+	if email.ReplyTo != "" {
+		merged.ReplyTo = email.ReplyTo
+	}
+
+	for _, h := range config.Headers {
+		if !slices.ContainsFunc(email.Headers, func(e parhamv1.EmailHeader) bool { return strings.EqualFold(e.Name, h.Name) }) {
+			merged.Headers = append(merged.Headers, h)
 		}
 	}
-	return id, nil
-}
+	merged.Headers = append(merged.Headers, email.Headers...)
 
-func sendEmailMessageMailerSend(ctx context.Context, senderConfig *parhamv1.EmailSenderConfig, recipientEmail, subject, body, apiToken string) (string, error) {
+	for _, tag := range append(append([]string{}, config.Tags...), email.Tags...) {
+		if !slices.Contains(merged.Tags, tag) {
+			merged.Tags = append(merged.Tags, tag)
+		}
+	}
 
-	ms := mailersend.NewMailersend(apiToken)
+	if len(config.Metadata)+len(email.Metadata) > 0 {
+		merged.Metadata = map[string]string{}
+		maps.Copy(merged.Metadata, config.Metadata)
+		maps.Copy(merged.Metadata, email.Metadata)
+	}
+	return merged
+}
 
-	sendCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
-	defer cancel()
+// validateMessageOptions checks the reply address and that no protected
+// header is set.
+func validateMessageOptions(options parhamv1.MessageOptions) error {
+	if options.ReplyTo != "" {
+		if _, err := provider.ParseAddress(options.ReplyTo); err != nil {
+			return fmt.Errorf("invalid reply-to address %q: %v", options.ReplyTo, err)
+		}
+	}
+	for _, h := range options.Headers {
+		if provider.IsProtectedHeader(h.Name) {
+			return fmt.Errorf("header %s cannot be set", h.Name)
+		}
+	}
+	return nil
+}
 
-	from := mailersend.From{
-		Name:  senderConfig.Spec.SenderEmail,
-		Email: senderConfig.Spec.SenderEmail,
+// applyMessageOptions sets the sender, with its display name, and the
+// options on msg.
+func applyMessageOptions(msg *provider.Message, senderEmail string, options parhamv1.MessageOptions) {
+	msg.From = senderEmail
+	if options.SenderName != "" {
+		msg.From = message.FormatAddressList([]mail.Address{{Name: options.SenderName, Address: provider.BareAddress(senderEmail)}})
 	}
+	msg.ReplyTo = options.ReplyTo
+	for _, h := range options.Headers {
+		msg.Headers = append(msg.Headers, provider.Header{Name: h.Name, Value: h.Value})
+	}
+	msg.Tags = options.Tags
+	msg.Metadata = options.Metadata
+}
 
-	recipients := []mailersend.Recipient{
-		{
-			Name:  "Recipient",
-			Email: recipientEmail,
-		},
+// newProvider builds and validates the provider selected by the sender config.
+// transport holds the manager defaults the sender config can override.
+func newProvider(ctx context.Context, c client.Client, transport provider.TransportOptions, senderConfig *parhamv1.EmailSenderConfig) (provider.Provider, error) {
+	var secret map[string][]byte
+	if senderConfig.Spec.ApiTokenSecretRef != "" {
+		var err error
+		secret, err = getSecretData(ctx, c, senderConfig.Namespace, senderConfig.Spec.ApiTokenSecretRef)
+		if err != nil {
+			return nil, fmt.Errorf("failed to retrieve API token: %v", err)
+		}
 	}
 
-	message := ms.Email.NewMessage()
-	message.SetFrom(from)
-	message.SetRecipients(recipients)
-	message.SetSubject(subject)
-	message.SetText(body)
+	options, err := transportOptions(ctx, c, senderConfig)
+	if err != nil {
+		return nil, fmt.Errorf("invalid transport configuration: %v", err)
+	}
 
-	res, err := ms.Email.Send(sendCtx, message)
+	p, err := provider.New(provider.Config{
+		Spec:       senderConfig.Spec,
+		SecretName: senderConfig.Spec.ApiTokenSecretRef,
+		Secret:     secret,
+		Transport:  options.Merge(transport),
+	})
 	if err != nil {
-		return "", err
+		return nil, fmt.Errorf("invalid provider configuration: %v", err)
+	}
+	if err := p.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid provider configuration: %v", err)
+	}
+	if _, err := provider.ParseAddress(senderConfig.Spec.SenderEmail); err != nil {
+		return nil, fmt.Errorf("invalid sender email: %v", err)
+	}
+	if err := validateMessageOptions(senderConfig.Spec.MessageOptions); err != nil {
+		return nil, fmt.Errorf("invalid message options: %v", err)
+	}
+	return p, nil
+}
+
+func getSecretData(ctx context.Context, c client.Client, namespace, secretName string) (map[string][]byte, error) {
+	var secret corev1.Secret
+	if err := c.Get(ctx, client.ObjectKey{Name: secretName, Namespace: namespace}, &secret); err != nil {
+		if err := c.Get(ctx, client.ObjectKey{Name: secretName, Namespace: "default"}, &secret); err != nil {
+			return nil, fmt.Errorf("unable to fetch secret %s: %v", secretName, err)
+		}
 	}
 
-	defer res.Body.Close()
+	return secret.Data, nil
+}
 
-	if res.StatusCode != http.StatusAccepted {
-		return "", fmt.Errorf("error response from MailerSend: %s", res.Status)
+// transportOptions reads the proxy credentials, CA bundle and client
+// certificate referenced by the sender config.
+func transportOptions(ctx context.Context, c client.Client, senderConfig *parhamv1.EmailSenderConfig) (provider.TransportOptions, error) {
+	var options provider.TransportOptions
+	spec := senderConfig.Spec.Transport
+	if spec == nil {
+		return options, nil
 	}
 
-	id := res.Header.Get("X-Message-Id")
+	options.ProxyURL = spec.ProxyURL
+	if spec.ProxySecretRef != "" {
+		data, err := getSecretData(ctx, c, senderConfig.Namespace, spec.ProxySecretRef)
+		if err != nil {
+			return options, err
+		}
+		options.ProxyUsername = string(data["username"])
+		options.ProxyPassword = string(data["password"])
+	}
+	if spec.CABundleConfigMapRef != "" {
+		data, err := getConfigMapData(ctx, c, senderConfig.Namespace, spec.CABundleConfigMapRef)
+		if err != nil {
+			return options, err
+		}
+		bundle, ok := data["ca.crt"]
+		if !ok {
+			return options, fmt.Errorf("configmap %s does not contain key ca.crt", spec.CABundleConfigMapRef)
+		}
+		options.CABundle = []byte(bundle)
+	}
+	if spec.ClientCertSecretRef != "" {
+		data, err := getSecretData(ctx, c, senderConfig.Namespace, spec.ClientCertSecretRef)
+		if err != nil {
+			return options, err
+		}
+		options.ClientCert = data[corev1.TLSCertKey]
+		options.ClientKey = data[corev1.TLSPrivateKeyKey]
+	}
+	return options, nil
+}
 
-	return id, nil
+func getConfigMapData(ctx context.Context, c client.Client, namespace, name string) (map[string]string, error) {
+	var configMap corev1.ConfigMap
+	if err := c.Get(ctx, client.ObjectKey{Name: name, Namespace: namespace}, &configMap); err != nil {
+		return nil, fmt.Errorf("unable to fetch configmap %s: %v", name, err)
+	}
+	return configMap.Data, nil
 }
 
-func sendEmailMessageMailgun(ctx context.Context, senderConfig *parhamv1.EmailSenderConfig, recipientEmail, subject, body, apiToken string) (string, error) {
+// DefaultMaxAttachmentSize bounds the total size of the attachments of an
+// Email when the reconciler does not set a limit.
+const DefaultMaxAttachmentSize = 10 << 20
+
+// resolveAttachments reads the content of every attachment from the
+// ConfigMap or Secret key it references, failing once the total size exceeds
+// maxSize bytes.
+func resolveAttachments(ctx context.Context, c client.Client, namespace string, list []parhamv1.EmailAttachment, maxSize int64) ([]provider.Attachment, error) {
+	var (
+		attachments []provider.Attachment
+		total       int64
+	)
+	for _, spec := range list {
+		data, err := keyData(ctx, c, namespace, spec.ConfigMapKeyRef, spec.SecretKeyRef)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read attachment %s: %v", spec.Filename, err)
+		}
+		total += int64(len(data))
+		if total > maxSize {
+			return nil, fmt.Errorf("attachments exceed the maximum total size of %d bytes", maxSize)
+		}
 
-	parts := strings.Split(senderConfig.Spec.SenderEmail, "@")
-	if len(parts) != 2 {
-		return "", errors.New("invalid sender email format")
+		contentType := spec.ContentType
+		if contentType == "" {
+			contentType = mime.TypeByExtension(filepath.Ext(spec.Filename))
+		}
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+		attachments = append(attachments, provider.Attachment{
+			Filename:    spec.Filename,
+			ContentType: contentType,
+			ContentID:   spec.ContentID,
+			Data:        data,
+		})
 	}
+	return attachments, nil
+}
 
-	mg := mailgun.NewMailgun(parts[1], apiToken)
-	sendCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
-	defer cancel()
+// keyData returns the value of the ConfigMap or the Secret key, whichever is
+// set.
+func keyData(ctx context.Context, c client.Client, namespace string, configMapKeyRef *corev1.ConfigMapKeySelector, secretKeyRef *corev1.SecretKeySelector) ([]byte, error) {
+	switch {
+	case configMapKeyRef != nil:
+		ref := configMapKeyRef
+		var configMap corev1.ConfigMap
+		if err := c.Get(ctx, client.ObjectKey{Name: ref.Name, Namespace: namespace}, &configMap); err != nil {
+			return nil, fmt.Errorf("unable to fetch configmap %s: %v", ref.Name, err)
+		}
+		if value, ok := configMap.Data[ref.Key]; ok {
+			return []byte(value), nil
+		}
+		if value, ok := configMap.BinaryData[ref.Key]; ok {
+			return value, nil
+		}
+		return nil, fmt.Errorf("configmap %s does not contain key %s", ref.Name, ref.Key)
+	case secretKeyRef != nil:
+		ref := secretKeyRef
+		var secret corev1.Secret
+		if err := c.Get(ctx, client.ObjectKey{Name: ref.Name, Namespace: namespace}, &secret); err != nil {
+			return nil, fmt.Errorf("unable to fetch secret %s: %v", ref.Name, err)
+		}
+		value, ok := secret.Data[ref.Key]
+		if !ok {
+			return nil, fmt.Errorf("secret %s does not contain key %s", ref.Name, ref.Key)
+		}
+		return value, nil
+	default:
+		return nil, errors.New("exactly one of configMapKeyRef and secretKeyRef must be set")
+	}
+}
 
-	from := senderConfig.Spec.SenderEmail
+// renderContent returns the subject and bodies of the email. They are
+// rendered as templates when the email references a template or sets
+// template data, a Markdown body is then rendered to HTML and the HTML body
+// wrapped in the layout of the email.
+func renderContent(ctx context.Context, c client.Client, email *parhamv1.Email) (render.Content, error) {
+	content, err := renderTemplates(ctx, c, email)
+	if err != nil {
+		return render.Content{}, err
+	}
 
-	m := mg.NewMessage(
-		from,
-		subject,
-		body,
-		recipientEmail,
-	)
+	if email.Spec.Format == parhamv1.FormatMarkdown {
+		if content.HTML != "" {
+			return render.Content{}, errors.New("html must not be set when format is markdown")
+		}
+		content.HTML, content.Text = markdown.Render(content.Text)
+	}
+	if email.Spec.Layout != "" && content.HTML != "" {
+		if content.HTML, err = render.Layout(email.Spec.Layout, content.Subject, content.HTML); err != nil {
+			return render.Content{}, fmt.Errorf("unable to apply layout: %v", err)
+		}
+	}
+	return content, nil
+}
 
-	_, id, err := mg.Send(sendCtx, m)
+// renderTemplates renders the subject and bodies of the email as templates
+// when it references a template or sets template data, and returns them
+// unchanged otherwise.
+func renderTemplates(ctx context.Context, c client.Client, email *parhamv1.Email) (render.Content, error) {
+	content := render.Content{Subject: email.Spec.Subject, Text: email.Spec.Body, HTML: email.Spec.HTML}
+	if email.Spec.TemplateRef == nil && len(email.Spec.TemplateData) == 0 {
+		return content, nil
+	}
 
+	data, err := templateData(ctx, c, email.Namespace, email.Spec.TemplateData)
+	if err != nil {
+		return render.Content{}, err
+	}
+	if ref := email.Spec.TemplateRef; ref != nil {
+		spec, err := getEmailTemplate(ctx, c, email.Namespace, ref)
+		if err != nil {
+			return render.Content{}, err
+		}
+		if missing := applyVariables(data, spec.Variables); len(missing) > 0 {
+			return render.Content{}, fmt.Errorf("missing required template variables: %s", strings.Join(missing, ", "))
+		}
+		if content.Subject == "" {
+			content.Subject = spec.Subject
+		}
+		if content.Text == "" && content.HTML == "" {
+			content.Text, content.HTML = spec.Text, spec.HTML
+		}
+	}
+	rendered, err := render.Render(content, data)
 	if err != nil {
-		return "", err
+		return render.Content{}, fmt.Errorf("unable to render template: %v", err)
 	}
+	return rendered, nil
+}
 
-	return id, nil
+// getEmailTemplate returns the spec of the EmailTemplate or
+// ClusterEmailTemplate referenced by ref.
+func getEmailTemplate(ctx context.Context, c client.Client, namespace string, ref *parhamv1.EmailTemplateReference) (*parhamv1.EmailTemplateSpec, error) {
+	if ref.Kind == clusterEmailTemplateKind {
+		var template parhamv1.ClusterEmailTemplate
+		if err := c.Get(ctx, client.ObjectKey{Name: ref.Name}, &template); err != nil {
+			return nil, fmt.Errorf("unable to fetch ClusterEmailTemplate %s: %v", ref.Name, err)
+		}
+		return &template.Spec, nil
+	}
+	var template parhamv1.EmailTemplate
+	if err := c.Get(ctx, client.ObjectKey{Name: ref.Name, Namespace: namespace}, &template); err != nil {
+		return nil, fmt.Errorf("unable to fetch EmailTemplate %s: %v", ref.Name, err)
+	}
+	return &template.Spec, nil
 }
 
-func getApiTokenFromSecret(ctx context.Context, c client.Client, namespace, secretName, secretKey string) (string, error) {
-	var secret corev1.Secret
-	if err := c.Get(ctx, client.ObjectKey{Name: secretName, Namespace: namespace}, &secret); err != nil {
-		if err := c.Get(ctx, client.ObjectKey{Name: secretName, Namespace: "default"}, &secret); err != nil {
-			return "", fmt.Errorf("unable to fetch secret %s: %v", secretName, err)
+// applyVariables sets the defaults of the variables missing from data and
+// returns the names of the required variables it is missing.
+func applyVariables(data map[string]any, variables []parhamv1.EmailTemplateVariable) []string {
+	var missing []string
+	for _, variable := range variables {
+		if _, ok := data[variable.Name]; ok {
+			continue
+		}
+		if variable.Required {
+			missing = append(missing, variable.Name)
+			continue
+		}
+		data[variable.Name] = variable.Default
+	}
+	return missing
+}
+
+// templateData resolves every template value of the list.
+func templateData(ctx context.Context, c client.Client, namespace string, list []parhamv1.EmailTemplateData) (map[string]any, error) {
+	data := make(map[string]any, len(list))
+	for _, spec := range list {
+		var value any
+		var err error
+		switch {
+		case spec.ObjectRef != nil:
+			value, err = objectData(ctx, c, namespace, spec.ObjectRef)
+		case spec.ConfigMapKeyRef != nil || spec.SecretKeyRef != nil:
+			var raw []byte
+			raw, err = keyData(ctx, c, namespace, spec.ConfigMapKeyRef, spec.SecretKeyRef)
+			value = string(raw)
+		default:
+			value = spec.Value
 		}
+		if err != nil {
+			return nil, fmt.Errorf("unable to read template data %s: %v", spec.Name, err)
+		}
+		data[spec.Name] = value
 	}
+	return data, nil
+}
 
-	tokenBase64, ok := secret.Data[secretKey]
-	if !ok {
-		return "", fmt.Errorf("secret %s does not contain key %s", secretName, secretKey)
+// objectData returns the field of the object selected by the JSONPath of
+// ref, or the whole object when ref has no JSONPath.
+func objectData(ctx context.Context, c client.Client, namespace string, ref *parhamv1.TemplateObjectReference) (any, error) {
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion(ref.APIVersion)
+	obj.SetKind(ref.Kind)
+	if err := c.Get(ctx, client.ObjectKey{Name: ref.Name, Namespace: namespace}, obj); err != nil {
+		return nil, fmt.Errorf("unable to fetch %s %s: %v", ref.Kind, ref.Name, err)
+	}
+	if ref.JSONPath == "" {
+		return obj.Object, nil
 	}
 
-	return string(tokenBase64), nil
+	path := ref.JSONPath
+	if !strings.HasPrefix(path, "{") {
+		path = "{" + path + "}"
+	}
+	parser := jsonpath.New(ref.Name)
+	if err := parser.Parse(path); err != nil {
+		return nil, fmt.Errorf("invalid jsonPath %q: %v", ref.JSONPath, err)
+	}
+	var out strings.Builder
+	if err := parser.Execute(&out, obj.Object); err != nil {
+		return nil, fmt.Errorf("unable to evaluate jsonPath %q on %s %s: %v", ref.JSONPath, ref.Kind, ref.Name, err)
+	}
+	return out.String(), nil
 }
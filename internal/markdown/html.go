@@ -0,0 +1,196 @@
+package markdown
+
+import (
+	"html"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// safeURL reports whether dest may be used as a link or image target.
+// Relative URLs and the http, https and mailto schemes are allowed, images
+// may also refer to inline attachments with cid.
+func safeURL(dest string, k kind) bool {
+	u, err := url.Parse(strings.TrimSpace(dest))
+	if err != nil {
+		return false
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "", "http", "https":
+		return true
+	case "mailto":
+		return k == link
+	case "cid":
+		return k == image
+	}
+	return false
+}
+
+func renderHTML(doc *node) string {
+	r := &htmlRenderer{}
+	r.blocks(doc.children, false)
+	return r.String()
+}
+
+type htmlRenderer struct {
+	strings.Builder
+	// inLink is set while rendering the text of a link, nested links are
+	// rendered as their text.
+	inLink bool
+}
+
+func (r *htmlRenderer) blocks(blocks []*node, tight bool) {
+	for _, b := range blocks {
+		r.block(b, tight)
+	}
+}
+
+func (r *htmlRenderer) block(b *node, tight bool) {
+	switch b.kind {
+	case paragraph:
+		if tight {
+			r.inlines(b.children)
+			return
+		}
+		r.WriteString("<p>")
+		r.inlines(b.children)
+		r.WriteString("</p>\n")
+
+	case heading:
+		tag := "h" + strconv.Itoa(b.level)
+		r.WriteString("<" + tag + ">")
+		r.inlines(b.children)
+		r.WriteString("</" + tag + ">\n")
+
+	case codeBlock:
+		r.WriteString("<pre><code")
+		if b.info != "" {
+			r.WriteString(` class="language-` + html.EscapeString(b.info) + `"`)
+		}
+		r.WriteString(">" + html.EscapeString(b.literal) + "</code></pre>\n")
+
+	case blockquote:
+		r.WriteString("<blockquote>\n")
+		r.blocks(b.children, false)
+		r.WriteString("</blockquote>\n")
+
+	case list:
+		tag := "ul"
+		if b.ordered {
+			tag = "ol"
+		}
+		r.WriteString("<" + tag)
+		if b.ordered && b.start != 1 {
+			r.WriteString(` start="` + strconv.Itoa(b.start) + `"`)
+		}
+		r.WriteString(">\n")
+		for _, it := range b.children {
+			r.WriteString("<li>")
+			if !b.tight && len(it.children) > 0 {
+				r.WriteString("\n")
+			}
+			for i, child := range it.children {
+				if b.tight && i > 0 && child.kind != paragraph {
+					r.WriteString("\n")
+				}
+				r.block(child, b.tight)
+			}
+			r.WriteString("</li>\n")
+		}
+		r.WriteString("</" + tag + ">\n")
+
+	case table:
+		r.WriteString("<table>\n")
+		for i, row := range b.children {
+			switch i {
+			case 0:
+				r.WriteString("<thead>\n")
+			case 1:
+				r.WriteString("<tbody>\n")
+			}
+			tag := "td"
+			if row.header {
+				tag = "th"
+			}
+			r.WriteString("<tr>\n")
+			for c, cell := range row.children {
+				r.WriteString("<" + tag)
+				if align := b.align[c]; align != "" {
+					r.WriteString(` style="text-align: ` + align + `"`)
+				}
+				r.WriteString(">")
+				r.inlines(cell.children)
+				r.WriteString("</" + tag + ">\n")
+			}
+			r.WriteString("</tr>\n")
+			if i == 0 {
+				r.WriteString("</thead>\n")
+			}
+		}
+		if len(b.children) > 1 {
+			r.WriteString("</tbody>\n")
+		}
+		r.WriteString("</table>\n")
+
+	case thematicBreak:
+		r.WriteString("<hr>\n")
+	}
+}
+
+func (r *htmlRenderer) inlines(inlines []*node) {
+	for _, n := range inlines {
+		r.inline(n)
+	}
+}
+
+func (r *htmlRenderer) inline(n *node) {
+	switch n.kind {
+	case text:
+		r.WriteString(html.EscapeString(n.literal))
+	case code:
+		r.WriteString("<code>" + html.EscapeString(n.literal) + "</code>")
+	case emphasis:
+		r.wrap("em", n.children)
+	case strong:
+		r.wrap("strong", n.children)
+	case strikethrough:
+		r.wrap("del", n.children)
+	case softBreak:
+		r.WriteString("\n")
+	case hardBreak:
+		r.WriteString("<br>\n")
+
+	case link:
+		if r.inLink || !safeURL(n.dest, link) {
+			r.inlines(n.children)
+			return
+		}
+		r.WriteString(`<a href="` + html.EscapeString(n.dest) + `"`)
+		if n.title != "" {
+			r.WriteString(` title="` + html.EscapeString(n.title) + `"`)
+		}
+		r.WriteString(">")
+		r.inLink = true
+		r.inlines(n.children)
+		r.inLink = false
+		r.WriteString("</a>")
+
+	case image:
+		alt := plainText(n.children)
+		if !safeURL(n.dest, image) {
+			r.WriteString(html.EscapeString(alt))
+			return
+		}
+		r.WriteString(`<img src="` + html.EscapeString(n.dest) + `" alt="` + html.EscapeString(alt) + `"`)
+		if n.title != "" {
+			r.WriteString(` title="` + html.EscapeString(n.title) + `"`)
+		}
+		r.WriteString(">")
+	}
+}
+
+func (r *htmlRenderer) wrap(tag string, children []*node) {
+	r.WriteString("<" + tag + ">")
+	r.inlines(children)
+	r.WriteString("</" + tag + ">")
+}
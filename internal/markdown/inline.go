@@ -0,0 +1,355 @@
+package markdown
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// parseInlines parses the inline content of a block.
+func parseInlines(s string) []*node {
+	p := &inlineParser{}
+	p.parse(s)
+	return p.nodes
+}
+
+type inlineParser struct {
+	nodes []*node
+	text  strings.Builder
+}
+
+func (p *inlineParser) flush() {
+	if p.text.Len() > 0 {
+		p.nodes = append(p.nodes, &node{kind: text, literal: p.text.String()})
+		p.text.Reset()
+	}
+}
+
+func (p *inlineParser) add(n *node) {
+	p.flush()
+	p.nodes = append(p.nodes, n)
+}
+
+func isPunct(c byte) bool {
+	return c < utf8.RuneSelf && unicode.IsPunct(rune(c)) || strings.IndexByte("$+<=>^`|~", c) >= 0
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\n' || c == '\t'
+}
+
+func isAlnum(c byte) bool {
+	return c >= utf8.RuneSelf || unicode.IsLetter(rune(c)) || unicode.IsDigit(rune(c))
+}
+
+func (p *inlineParser) parse(s string) {
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == '\\' && i+1 < len(s) && s[i+1] == '\n':
+			p.add(&node{kind: hardBreak})
+			i += 2
+
+		case c == '\\' && i+1 < len(s) && isPunct(s[i+1]):
+			p.text.WriteByte(s[i+1])
+			i += 2
+
+		case c == '\n':
+			// Two trailing spaces make a hard line break.
+			current := p.text.String()
+			trimmed := strings.TrimRight(current, " ")
+			p.text.Reset()
+			p.text.WriteString(trimmed)
+			if len(current)-len(trimmed) >= 2 {
+				p.add(&node{kind: hardBreak})
+			} else {
+				p.add(&node{kind: softBreak})
+			}
+			i++
+			for i < len(s) && s[i] == ' ' {
+				i++
+			}
+
+		case c == '`':
+			n, end := codeSpan(s, i)
+			if n == nil {
+				p.text.WriteString(s[i:end])
+			} else {
+				p.add(n)
+			}
+			i = end
+
+		case c == '!' && i+1 < len(s) && s[i+1] == '[':
+			if n, end := linkOrImage(s, i+1, image); n != nil {
+				p.add(n)
+				i = end
+			} else {
+				p.text.WriteByte(c)
+				i++
+			}
+
+		case c == '[':
+			if n, end := linkOrImage(s, i, link); n != nil {
+				p.add(n)
+				i = end
+			} else {
+				p.text.WriteByte(c)
+				i++
+			}
+
+		case c == '<':
+			if n, end := autolink(s, i); n != nil {
+				p.add(n)
+				i = end
+			} else {
+				p.text.WriteByte(c)
+				i++
+			}
+
+		case c == '*' || c == '_' || c == '~':
+			if n, end := delimited(s, i); n != nil {
+				p.add(n)
+				i = end
+			} else {
+				run := runLength(s, i)
+				p.text.WriteString(s[i : i+run])
+				i += run
+			}
+
+		case (c == 'h' || c == 'H') && (i == 0 || !isAlnum(s[i-1])) && hasURLPrefix(s[i:]):
+			end := bareURLEnd(s, i)
+			dest := s[i:end]
+			p.add(&node{kind: link, dest: dest, children: []*node{{kind: text, literal: dest}}})
+			i = end
+
+		default:
+			p.text.WriteByte(c)
+			i++
+		}
+	}
+	p.flush()
+}
+
+func runLength(s string, i int) int {
+	n := 1
+	for i+n < len(s) && s[i+n] == s[i] {
+		n++
+	}
+	return n
+}
+
+// codeSpan parses the code span opened by the backtick run at i. It returns
+// a nil node and the end of the run when the span is not closed.
+func codeSpan(s string, i int) (*node, int) {
+	n := runLength(s, i)
+	for j := i + n; j < len(s); {
+		if s[j] != '`' {
+			j++
+			continue
+		}
+		m := runLength(s, j)
+		if m == n {
+			content := strings.ReplaceAll(s[i+n:j], "\n", " ")
+			if len(content) > 2 && content[0] == ' ' && content[len(content)-1] == ' ' && strings.TrimSpace(content) != "" {
+				content = content[1 : len(content)-1]
+			}
+			return &node{kind: code, literal: content}, j + m
+		}
+		j += m
+	}
+	return nil, i + n
+}
+
+// skipCode returns the end of the code span at i, or i+1 when there is none.
+func skipCode(s string, i int) int {
+	if n, end := codeSpan(s, i); n != nil {
+		return end
+	}
+	return i + 1
+}
+
+// delimited parses emphasis, strong emphasis or strikethrough opened by the
+// delimiter run at i.
+func delimited(s string, i int) (*node, int) {
+	c := s[i]
+	run := runLength(s, i)
+	if i+run >= len(s) || isSpace(s[i+run]) {
+		return nil, 0
+	}
+	if c == '_' && i > 0 && isAlnum(s[i-1]) {
+		return nil, 0
+	}
+
+	width, k := 1, emphasis
+	switch {
+	case c == '~' && run == 2:
+		width, k = 2, strikethrough
+	case c == '~':
+		return nil, 0
+	case run >= 2:
+		width, k = 2, strong
+	}
+
+	for j := i + width; j < len(s); {
+		switch {
+		case s[j] == '\\':
+			j += 2
+			continue
+		case s[j] == '`':
+			j = skipCode(s, j)
+			continue
+		case s[j] != c:
+			j++
+			continue
+		}
+		m := runLength(s, j)
+		closes := !isSpace(s[j-1]) && j > i+width
+		if c == '_' && j+m < len(s) && isAlnum(s[j+m]) {
+			closes = false
+		}
+		if closes && (m == width || m >= 3) {
+			// A longer closing run closes with its last delimiters, the
+			// others belong to the content.
+			end := j + m - width
+			return &node{kind: k, children: parseInlines(s[i+width : end])}, j + m
+		}
+		j += m
+	}
+	return nil, 0
+}
+
+// linkOrImage parses a link or an image whose text starts with the bracket
+// at i.
+func linkOrImage(s string, i int, k kind) (*node, int) {
+	depth := 0
+	closing := -1
+	for j := i; j < len(s) && closing < 0; {
+		switch s[j] {
+		case '\\':
+			j += 2
+			continue
+		case '`':
+			j = skipCode(s, j)
+			continue
+		case '[':
+			depth++
+		case ']':
+			depth--
+			if depth == 0 {
+				closing = j
+			}
+		}
+		j++
+	}
+	if closing < 0 || closing+1 >= len(s) || s[closing+1] != '(' {
+		return nil, 0
+	}
+
+	j := closing + 2
+	for j < len(s) && isSpace(s[j]) {
+		j++
+	}
+	var dest string
+	if j < len(s) && s[j] == '<' {
+		end := strings.IndexAny(s[j:], ">\n")
+		if end < 0 || s[j+end] != '>' {
+			return nil, 0
+		}
+		dest = s[j+1 : j+end]
+		j += end + 1
+	} else {
+		start, parens := j, 0
+		for ; j < len(s) && !isSpace(s[j]); j++ {
+			if s[j] == '\\' && j+1 < len(s) {
+				j++
+				continue
+			}
+			if s[j] == '(' {
+				parens++
+			}
+			if s[j] == ')' {
+				if parens == 0 {
+					break
+				}
+				parens--
+			}
+		}
+		dest = unescape(s[start:j])
+	}
+
+	for j < len(s) && isSpace(s[j]) {
+		j++
+	}
+	var title string
+	if j < len(s) && (s[j] == '"' || s[j] == '\'') {
+		end := strings.IndexByte(s[j+1:], s[j])
+		if end < 0 {
+			return nil, 0
+		}
+		title = unescape(s[j+1 : j+1+end])
+		j += end + 2
+		for j < len(s) && isSpace(s[j]) {
+			j++
+		}
+	}
+	if j >= len(s) || s[j] != ')' {
+		return nil, 0
+	}
+	return &node{kind: k, dest: dest, title: title, children: parseInlines(s[i+1 : closing])}, j + 1
+}
+
+func unescape(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) && isPunct(s[i+1]) {
+			i++
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+// autolink parses <scheme:...> and <user@example.com> autolinks.
+func autolink(s string, i int) (*node, int) {
+	end := strings.IndexAny(s[i+1:], "<> \n")
+	if end < 0 || s[i+1+end] != '>' {
+		return nil, 0
+	}
+	target := s[i+1 : i+1+end]
+	dest := target
+	switch {
+	case hasURLPrefix(target) || strings.HasPrefix(strings.ToLower(target), "mailto:"):
+	case strings.Count(target, "@") == 1 && !strings.Contains(target, ":"):
+		dest = "mailto:" + target
+	default:
+		return nil, 0
+	}
+	return &node{kind: link, dest: dest, children: []*node{{kind: text, literal: target}}}, i + end + 2
+}
+
+func hasURLPrefix(s string) bool {
+	lower := strings.ToLower(s[:min(len(s), 8)])
+	return strings.HasPrefix(lower, "http://") || strings.HasPrefix(lower, "https://")
+}
+
+// bareURLEnd returns the end of the URL at i, leaving out trailing
+// punctuation and unbalanced closing parentheses.
+func bareURLEnd(s string, i int) int {
+	end := i
+	for end < len(s) && !isSpace(s[end]) && s[end] != '<' {
+		end++
+	}
+	for end > i {
+		last := s[end-1]
+		if strings.IndexByte(".,:;!?\"'*_~", last) >= 0 {
+			end--
+			continue
+		}
+		if last == ')' && strings.Count(s[i:end], "(") < strings.Count(s[i:end], ")") {
+			end--
+			continue
+		}
+		break
+	}
+	return end
+}
@@ -0,0 +1,329 @@
+// Package markdown renders a safe subset of GitHub flavored Markdown to an
+// HTML fragment and a matching plain text body. Raw HTML is escaped rather
+// than passed through, and links and images are only kept for schemes that
+// are safe in email clients.
+package markdown
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+type kind int
+
+const (
+	// Blocks
+	document kind = iota
+	paragraph
+	heading
+	codeBlock
+	blockquote
+	list
+	item
+	table
+	tableRow
+	tableCell
+	thematicBreak
+
+	// Inlines
+	text
+	code
+	emphasis
+	strong
+	strikethrough
+	link
+	image
+	softBreak
+	hardBreak
+)
+
+// node is an element of the document tree.
+type node struct {
+	kind     kind
+	children []*node
+
+	// level is the level of a heading.
+	level int
+	// literal is the content of text, code spans and code blocks.
+	literal string
+	// info is the language of a fenced code block.
+	info string
+	// dest and title are the target and title of links and images.
+	dest, title string
+
+	// ordered, start and tight describe lists.
+	ordered bool
+	start   int
+	tight   bool
+
+	// header marks the header row of a table, align lists the alignment of
+	// every column.
+	header bool
+	align  []string
+}
+
+// Render converts Markdown source to an HTML fragment and its plain text
+// rendering.
+func Render(source string) (html, text string) {
+	source = strings.ReplaceAll(source, "\r\n", "\n")
+	source = strings.ReplaceAll(source, "\t", "    ")
+	doc := &node{kind: document, children: parseBlocks(strings.Split(source, "\n"))}
+	return renderHTML(doc), renderText(doc)
+}
+
+var (
+	atxHeading     = regexp.MustCompile(`^ {0,3}(#{1,6})(?:[ \t]+(.*?))?(?:[ \t]+#+)?[ \t]*$`)
+	thematic       = regexp.MustCompile(`^ {0,3}(?:(?:\*[ \t]*){3,}|(?:-[ \t]*){3,}|(?:_[ \t]*){3,})$`)
+	setextH1       = regexp.MustCompile(`^ {0,3}=+[ \t]*$`)
+	setextH2       = regexp.MustCompile(`^ {0,3}-+[ \t]*$`)
+	fence          = regexp.MustCompile("^( {0,3})(`{3,}|~{3,})[ \t]*([^`]*)$")
+	quoteMarker    = regexp.MustCompile(`^ {0,3}> ?`)
+	listMarker     = regexp.MustCompile(`^( {0,3})([-*+]|\d{1,9}[.)])( +|$)`)
+	tableDelimiter = regexp.MustCompile(`^ {0,3}\|?[ \t]*:?-+:?[ \t]*(?:\|[ \t]*:?-+:?[ \t]*)*\|?[ \t]*$`)
+)
+
+func isBlank(line string) bool {
+	return strings.TrimSpace(line) == ""
+}
+
+func indentation(line string) int {
+	return len(line) - len(strings.TrimLeft(line, " "))
+}
+
+// parseBlocks parses lines into block nodes.
+func parseBlocks(lines []string) []*node {
+	var blocks []*node
+	for i := 0; i < len(lines); {
+		line := lines[i]
+		switch {
+		case isBlank(line):
+			i++
+
+		case fence.MatchString(line):
+			var block *node
+			block, i = parseFence(lines, i)
+			blocks = append(blocks, block)
+
+		case atxHeading.MatchString(line):
+			m := atxHeading.FindStringSubmatch(line)
+			blocks = append(blocks, &node{kind: heading, level: len(m[1]), children: parseInlines(strings.TrimSpace(m[2]))})
+			i++
+
+		case thematic.MatchString(line):
+			blocks = append(blocks, &node{kind: thematicBreak})
+			i++
+
+		case quoteMarker.MatchString(line):
+			var inner []string
+			for ; i < len(lines) && quoteMarker.MatchString(lines[i]); i++ {
+				inner = append(inner, quoteMarker.ReplaceAllString(lines[i], ""))
+			}
+			blocks = append(blocks, &node{kind: blockquote, children: parseBlocks(inner)})
+
+		case listMarker.MatchString(line):
+			var block *node
+			block, i = parseList(lines, i)
+			blocks = append(blocks, block)
+
+		case indentation(line) >= 4:
+			var literal []string
+			for ; i < len(lines) && (isBlank(lines[i]) || indentation(lines[i]) >= 4); i++ {
+				if len(lines[i]) >= 4 {
+					literal = append(literal, lines[i][4:])
+				} else {
+					literal = append(literal, "")
+				}
+			}
+			for len(literal) > 0 && isBlank(literal[len(literal)-1]) {
+				literal = literal[:len(literal)-1]
+			}
+			blocks = append(blocks, &node{kind: codeBlock, literal: strings.Join(literal, "\n") + "\n"})
+
+		case i+1 < len(lines) && strings.Contains(line, "|") && tableDelimiter.MatchString(lines[i+1]) &&
+			len(splitRow(line)) == len(splitRow(lines[i+1])):
+			var block *node
+			block, i = parseTable(lines, i)
+			blocks = append(blocks, block)
+
+		default:
+			var block *node
+			block, i = parseParagraph(lines, i)
+			blocks = append(blocks, block)
+		}
+	}
+	return blocks
+}
+
+// interrupts reports whether line starts a block that ends a paragraph.
+func interrupts(line string) bool {
+	if fence.MatchString(line) || atxHeading.MatchString(line) || thematic.MatchString(line) || quoteMarker.MatchString(line) {
+		return true
+	}
+	m := listMarker.FindStringSubmatch(line)
+	return m != nil && !isBlank(line[len(m[0]):])
+}
+
+func parseParagraph(lines []string, i int) (*node, int) {
+	var content []string
+	for ; i < len(lines) && !isBlank(lines[i]); i++ {
+		line := lines[i]
+		if len(content) > 0 {
+			if setextH1.MatchString(line) {
+				return &node{kind: heading, level: 1, children: parseInlines(strings.Join(content, "\n"))}, i + 1
+			}
+			if setextH2.MatchString(line) {
+				return &node{kind: heading, level: 2, children: parseInlines(strings.Join(content, "\n"))}, i + 1
+			}
+			if interrupts(line) {
+				break
+			}
+		}
+		content = append(content, strings.TrimLeft(line, " "))
+	}
+	return &node{kind: paragraph, children: parseInlines(strings.TrimRight(strings.Join(content, "\n"), " "))}, i
+}
+
+func parseFence(lines []string, i int) (*node, int) {
+	m := fence.FindStringSubmatch(lines[i])
+	indent, marker := len(m[1]), m[2]
+	info, _, _ := strings.Cut(strings.TrimSpace(m[3]), " ")
+
+	var literal []string
+	for i++; i < len(lines); i++ {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+		if indentation(line) < 4 && strings.HasPrefix(trimmed, marker) && strings.Trim(trimmed, marker[:1]) == "" {
+			i++
+			break
+		}
+		strip := min(indent, indentation(line))
+		literal = append(literal, line[strip:])
+	}
+	block := &node{kind: codeBlock, info: info}
+	if len(literal) > 0 {
+		block.literal = strings.Join(literal, "\n") + "\n"
+	}
+	return block, i
+}
+
+func parseList(lines []string, i int) (*node, int) {
+	first := listMarker.FindStringSubmatch(lines[i])
+	l := &node{kind: list, tight: true}
+	if n, err := strconv.Atoi(first[2][:len(first[2])-1]); err == nil {
+		l.ordered, l.start = true, n
+	}
+	delimiter := first[2][len(first[2])-1:]
+
+	blankBetween := false
+	for i < len(lines) {
+		m := listMarker.FindStringSubmatch(lines[i])
+		if m == nil || m[2][len(m[2])-1:] != delimiter {
+			break
+		}
+		if _, err := strconv.Atoi(m[2][:len(m[2])-1]); (err == nil) != l.ordered {
+			break
+		}
+		if blankBetween {
+			l.tight = false
+		}
+
+		// Continuation lines are indented to the content of the first line.
+		contentIndent := len(m[0])
+		if len(m[3]) > 4 {
+			contentIndent = len(m[1]) + len(m[2]) + 1
+		}
+		content := []string{strings.TrimLeft(lines[i][len(m[0]):], " ")}
+		blankInside, pendingBlank := false, false
+	continuation:
+		for i++; i < len(lines); i++ {
+			line := lines[i]
+			switch {
+			case isBlank(line):
+				pendingBlank = true
+				content = append(content, "")
+				continue
+			case indentation(line) >= contentIndent:
+				content = append(content, line[contentIndent:])
+			case !pendingBlank && !interrupts(line) && !listMarker.MatchString(line):
+				// A lazy continuation line of the paragraph.
+				content = append(content, line)
+			default:
+				break continuation
+			}
+			if pendingBlank {
+				blankInside = true
+				pendingBlank = false
+			}
+		}
+		for len(content) > 0 && isBlank(content[len(content)-1]) {
+			content = content[:len(content)-1]
+		}
+		if blankInside {
+			l.tight = false
+		}
+		blankBetween = pendingBlank
+		l.children = append(l.children, &node{kind: item, children: parseBlocks(content)})
+	}
+	return l, i
+}
+
+// splitRow returns the cells of a table row.
+func splitRow(line string) []string {
+	line = strings.TrimSpace(line)
+	line = strings.TrimPrefix(line, "|")
+	if strings.HasSuffix(line, "|") && !strings.HasSuffix(line, `\|`) {
+		line = line[:len(line)-1]
+	}
+
+	var cells []string
+	var cell strings.Builder
+	for i := 0; i < len(line); i++ {
+		switch {
+		case line[i] == '\\' && i+1 < len(line) && line[i+1] == '|':
+			cell.WriteByte('|')
+			i++
+		case line[i] == '|':
+			cells = append(cells, strings.TrimSpace(cell.String()))
+			cell.Reset()
+		default:
+			cell.WriteByte(line[i])
+		}
+	}
+	return append(cells, strings.TrimSpace(cell.String()))
+}
+
+func parseTable(lines []string, i int) (*node, int) {
+	t := &node{kind: table}
+	for _, delimiter := range splitRow(lines[i+1]) {
+		switch {
+		case strings.HasPrefix(delimiter, ":") && strings.HasSuffix(delimiter, ":"):
+			t.align = append(t.align, "center")
+		case strings.HasSuffix(delimiter, ":"):
+			t.align = append(t.align, "right")
+		case strings.HasPrefix(delimiter, ":"):
+			t.align = append(t.align, "left")
+		default:
+			t.align = append(t.align, "")
+		}
+	}
+
+	row := func(line string, header bool) *node {
+		r := &node{kind: tableRow, header: header}
+		cells := splitRow(line)
+		for c := range t.align {
+			cell := &node{kind: tableCell}
+			if c < len(cells) {
+				cell.children = parseInlines(cells[c])
+			}
+			r.children = append(r.children, cell)
+		}
+		return r
+	}
+
+	t.children = append(t.children, row(lines[i], true))
+	for i += 2; i < len(lines) && !isBlank(lines[i]) && !interrupts(lines[i]); i++ {
+		t.children = append(t.children, row(lines[i], false))
+	}
+	return t, i
+}
@@ -0,0 +1,72 @@
+package markdown_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/parhamds/Email-Operator/internal/markdown"
+)
+
+var _ = Describe("Render", func() {
+	DescribeTable("should render Markdown to HTML and plain text",
+		func(source, html, text string) {
+			gotHTML, gotText := markdown.Render(source)
+			Expect(gotHTML).To(Equal(html))
+			Expect(gotText).To(Equal(text))
+		},
+		Entry("headings",
+			"# Release\n\nNotes\n-----",
+			"<h1>Release</h1>\n<h2>Notes</h2>\n",
+			"Release\n=======\n\nNotes\n-----"),
+		Entry("inline markup",
+			"Some *em*, __strong__, ~~old~~ and `a < b` text.  \nNext line",
+			"<p>Some <em>em</em>, <strong>strong</strong>, <del>old</del> and <code>a &lt; b</code> text.<br>\nNext line</p>\n",
+			"Some em, strong, old and a < b text.\nNext line"),
+		Entry("intraword underscores",
+			"snake_case_name and ***both***",
+			"<p>snake_case_name and <strong><em>both</em></strong></p>\n",
+			"snake_case_name and both"),
+		Entry("links",
+			"[docs](https://example.com/docs \"Docs\"), <ops@example.com> and https://example.com/status.",
+			"<p><a href=\"https://example.com/docs\" title=\"Docs\">docs</a>, <a href=\"mailto:ops@example.com\">ops@example.com</a> and <a href=\"https://example.com/status\">https://example.com/status</a>.</p>\n",
+			"docs (https://example.com/docs), ops@example.com and https://example.com/status."),
+		Entry("fenced code blocks",
+			"```go\nfunc main() {\n\tfmt.Println(\"<hi>\")\n}\n```",
+			"<pre><code class=\"language-go\">func main() {\n    fmt.Println(&#34;&lt;hi&gt;&#34;)\n}\n</code></pre>\n",
+			"    func main() {\n        fmt.Println(\"<hi>\")\n    }"),
+		Entry("indented code blocks",
+			"    kubectl get emails",
+			"<pre><code>kubectl get emails\n</code></pre>\n",
+			"    kubectl get emails"),
+		Entry("tight lists",
+			"- one\n- two\n  - nested\n\n3. three\n4. four",
+			"<ul>\n<li>one</li>\n<li>two\n<ul>\n<li>nested</li>\n</ul>\n</li>\n</ul>\n<ol start=\"3\">\n<li>three</li>\n<li>four</li>\n</ol>\n",
+			"- one\n- two\n  - nested\n\n3. three\n4. four"),
+		Entry("loose lists",
+			"* one\n\n* two",
+			"<ul>\n<li>\n<p>one</p>\n</li>\n<li>\n<p>two</p>\n</li>\n</ul>\n",
+			"- one\n\n- two"),
+		Entry("tables",
+			"| Name | Total |\n|:-----|------:|\n| Jane | 10 |\n| John \\| Doe | 200 |",
+			"<table>\n<thead>\n<tr>\n<th style=\"text-align: left\">Name</th>\n<th style=\"text-align: right\">Total</th>\n</tr>\n</thead>\n"+
+				"<tbody>\n<tr>\n<td style=\"text-align: left\">Jane</td>\n<td style=\"text-align: right\">10</td>\n</tr>\n"+
+				"<tr>\n<td style=\"text-align: left\">John | Doe</td>\n<td style=\"text-align: right\">200</td>\n</tr>\n</tbody>\n</table>\n",
+			"Name       | Total\n-----------|------\nJane       |    10\nJohn | Doe |   200"),
+		Entry("block quotes and thematic breaks",
+			"> Heads up\n> twice\n\n***",
+			"<blockquote>\n<p>Heads up\ntwice</p>\n</blockquote>\n<hr>\n",
+			"> Heads up\n> twice\n\n---"),
+	)
+
+	It("should escape raw HTML", func() {
+		html, text := markdown.Render("<script>alert(1)</script> <b>bold</b>")
+		Expect(html).To(Equal("<p>&lt;script&gt;alert(1)&lt;/script&gt; &lt;b&gt;bold&lt;/b&gt;</p>\n"))
+		Expect(text).To(Equal("<script>alert(1)</script> <b>bold</b>"))
+	})
+
+	It("should drop links and images with unsafe URLs", func() {
+		html, text := markdown.Render("[click](javascript:alert(1)) ![pixel](data:image/png;base64,AAAA) ![logo](cid:logo)")
+		Expect(html).To(Equal("<p>click pixel <img src=\"cid:logo\" alt=\"logo\"></p>\n"))
+		Expect(text).To(Equal("click pixel logo"))
+	})
+})
@@ -0,0 +1,165 @@
+package markdown
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// plainText returns the text of inlines without any markup.
+func plainText(inlines []*node) string {
+	var b strings.Builder
+	for _, n := range inlines {
+		switch n.kind {
+		case text, code:
+			b.WriteString(n.literal)
+		case softBreak, hardBreak:
+			b.WriteString("\n")
+		default:
+			b.WriteString(plainText(n.children))
+		}
+	}
+	return b.String()
+}
+
+// renderText renders the document as the plain text alternative of its
+// HTML rendering: blocks are separated by blank lines, links are followed
+// by their target and code keeps its layout.
+func renderText(doc *node) string {
+	return strings.TrimRight(textBlocks(doc.children, false), "\n")
+}
+
+func textBlocks(blocks []*node, tight bool) string {
+	var parts []string
+	for _, b := range blocks {
+		parts = append(parts, textBlock(b))
+	}
+	sep := "\n\n"
+	if tight {
+		sep = "\n"
+	}
+	return strings.Join(parts, sep)
+}
+
+func textBlock(b *node) string {
+	switch b.kind {
+	case paragraph:
+		return textInlines(b.children)
+
+	case heading:
+		title := textInlines(b.children)
+		switch b.level {
+		case 1:
+			return title + "\n" + strings.Repeat("=", max(utf8.RuneCountInString(title), 3))
+		case 2:
+			return title + "\n" + strings.Repeat("-", max(utf8.RuneCountInString(title), 3))
+		}
+		return title
+
+	case codeBlock:
+		return indent(strings.TrimRight(b.literal, "\n"), "    ", "    ")
+
+	case blockquote:
+		return indent(textBlocks(b.children, false), "> ", "> ")
+
+	case list:
+		var items []string
+		for i, it := range b.children {
+			marker := "- "
+			if b.ordered {
+				marker = strconv.Itoa(b.start+i) + ". "
+			}
+			items = append(items, indent(textBlocks(it.children, b.tight), marker, strings.Repeat(" ", len(marker))))
+		}
+		if b.tight {
+			return strings.Join(items, "\n")
+		}
+		return strings.Join(items, "\n\n")
+
+	case table:
+		return textTable(b)
+
+	case thematicBreak:
+		return "---"
+	}
+	return ""
+}
+
+// indent prefixes the first line of s with first and the others with rest.
+// Blank lines are left empty.
+func indent(s, first, rest string) string {
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		prefix := rest
+		if i == 0 {
+			prefix = first
+		}
+		if line == "" {
+			lines[i] = strings.TrimRight(prefix, " ")
+			continue
+		}
+		lines[i] = prefix + line
+	}
+	return strings.Join(lines, "\n")
+}
+
+// textTable lays the table out in padded columns.
+func textTable(t *node) string {
+	var rows [][]string
+	widths := make([]int, len(t.align))
+	for _, row := range t.children {
+		var cells []string
+		for c, cell := range row.children {
+			value := strings.ReplaceAll(textInlines(cell.children), "\n", " ")
+			widths[c] = max(widths[c], utf8.RuneCountInString(value))
+			cells = append(cells, value)
+		}
+		rows = append(rows, cells)
+	}
+
+	var lines []string
+	for r, cells := range rows {
+		var line []string
+		for c, value := range cells {
+			pad := strings.Repeat(" ", widths[c]-utf8.RuneCountInString(value))
+			if t.align[c] == "right" {
+				line = append(line, pad+value)
+			} else {
+				line = append(line, value+pad)
+			}
+		}
+		lines = append(lines, strings.TrimRight(strings.Join(line, " | "), " "))
+		if r == 0 {
+			var rule []string
+			for _, width := range widths {
+				rule = append(rule, strings.Repeat("-", max(width, 1)))
+			}
+			lines = append(lines, strings.Join(rule, "-|-"))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func textInlines(inlines []*node) string {
+	var b strings.Builder
+	for _, n := range inlines {
+		switch n.kind {
+		case text, code:
+			b.WriteString(n.literal)
+		case softBreak, hardBreak:
+			b.WriteString("\n")
+		case link:
+			label := textInlines(n.children)
+			b.WriteString(label)
+			dest := strings.TrimPrefix(n.dest, "mailto:")
+			if safeURL(n.dest, link) && dest != label && !strings.HasPrefix(n.dest, "#") {
+				b.WriteString(" (" + n.dest + ")")
+			}
+		case image:
+			b.WriteString(plainText(n.children))
+		default:
+			b.WriteString(textInlines(n.children))
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,206 @@
+package message
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"net/mail"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxLineLength is the line length headers are folded at, and the length of
+// base64 lines.
+const maxLineLength = 76
+
+// maxEncodedWordLength keeps encoded words short enough to fit on a line
+// after the header name.
+const maxEncodedWordLength = 60
+
+// encodeWords returns s as RFC 2047 encoded words when it is not printable
+// ASCII, in the shorter of the Q and B encodings.
+func encodeWords(s string) string {
+	printable := true
+	for i := 0; i < len(s); i++ {
+		if s[i] < ' ' || s[i] > '~' {
+			printable = false
+			break
+		}
+	}
+	if printable {
+		return s
+	}
+	encode := qEncode
+	if len(bEncode(s)) < len(qEncode(s)) {
+		encode = bEncode
+	}
+
+	// Words are split between characters, never inside one.
+	var words []string
+	start := 0
+	for i := 0; i < len(s); {
+		_, size := utf8.DecodeRuneInString(s[i:])
+		if i > start && len(encode(s[start:i+size])) > maxEncodedWordLength {
+			words = append(words, encode(s[start:i]))
+			start = i
+		}
+		i += size
+	}
+	return strings.Join(append(words, encode(s[start:])), " ")
+}
+
+func qEncode(s string) string {
+	var b strings.Builder
+	b.WriteString("=?utf-8?q?")
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == ' ':
+			b.WriteByte('_')
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9', strings.IndexByte("!*+-/", c) >= 0:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "=%02X", c)
+		}
+	}
+	b.WriteString("?=")
+	return b.String()
+}
+
+func bEncode(s string) string {
+	return "=?utf-8?b?" + base64.StdEncoding.EncodeToString([]byte(s)) + "?="
+}
+
+// FormatAddressList returns addrs as an RFC 5322 header value, with the
+// display names quoted or encoded as needed.
+func FormatAddressList(addrs []mail.Address) string {
+	formatted := make([]string, len(addrs))
+	for i, addr := range addrs {
+		if addr.Name == "" {
+			formatted[i] = addr.Address
+		} else {
+			formatted[i] = addr.String()
+		}
+	}
+	return strings.Join(formatted, ", ")
+}
+
+// writeHeader writes the header folded at whitespace into lines of at most
+// maxLineLength characters where possible.
+func writeHeader(buf *bytes.Buffer, name, value string) {
+	line := name + ":"
+	for i, word := range strings.Split(value, " ") {
+		if i > 0 && len(line)+1+len(word) > maxLineLength && strings.TrimSpace(line) != "" {
+			buf.WriteString(line + "\r\n")
+			line = ""
+		}
+		line += " " + word
+	}
+	buf.WriteString(line + "\r\n")
+}
+
+// entity is a MIME entity with its encoded body.
+type entity struct {
+	header []Header
+	body   []byte
+}
+
+// textEntity returns body with CRLF line endings. Bodies of ASCII lines of
+// at most 998 characters are sent as 7bit, others quoted-printable.
+func textEntity(contentType, body string) entity {
+	body = strings.ReplaceAll(body, "\r\n", "\n")
+	if !strings.HasSuffix(body, "\n") {
+		body += "\n"
+	}
+
+	encoding := "7bit"
+	for _, line := range strings.Split(body, "\n") {
+		if len(line) > 998 || !isASCII(line) {
+			encoding = "quoted-printable"
+			break
+		}
+	}
+
+	var buf bytes.Buffer
+	if encoding == "7bit" {
+		buf.WriteString(strings.ReplaceAll(body, "\n", "\r\n"))
+	} else {
+		qp := quotedprintable.NewWriter(&buf)
+		_, _ = qp.Write([]byte(strings.ReplaceAll(body, "\n", "\r\n")))
+		_ = qp.Close()
+	}
+	return entity{
+		header: []Header{
+			{Name: "Content-Type", Value: contentType},
+			{Name: "Content-Transfer-Encoding", Value: encoding},
+		},
+		body: buf.Bytes(),
+	}
+}
+
+// attachmentEntity returns the attachment base64 encoded in lines of
+// maxLineLength characters.
+func attachmentEntity(a Attachment) entity {
+	contentType := a.ContentType
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	disposition := "attachment"
+	if a.ContentID != "" {
+		disposition = "inline"
+	}
+
+	header := []Header{
+		{Name: "Content-Type", Value: mime.FormatMediaType(contentType, map[string]string{"name": a.Filename})},
+		{Name: "Content-Transfer-Encoding", Value: "base64"},
+		{Name: "Content-Disposition", Value: mime.FormatMediaType(disposition, map[string]string{"filename": a.Filename})},
+	}
+	if a.ContentID != "" {
+		header = append(header, Header{Name: "Content-ID", Value: "<" + a.ContentID + ">"})
+	}
+
+	encoded := base64.StdEncoding.EncodeToString(a.Data)
+	var buf bytes.Buffer
+	for len(encoded) > maxLineLength {
+		buf.WriteString(encoded[:maxLineLength] + "\r\n")
+		encoded = encoded[maxLineLength:]
+	}
+	buf.WriteString(encoded + "\r\n")
+	return entity{header: header, body: buf.Bytes()}
+}
+
+// multipart returns parts as a multipart entity of the given subtype. The
+// boundary starts with "=_", which cannot occur in quoted-printable or
+// base64 content, followed by random characters.
+func (b *Builder) multipart(subtype string, parts []entity) entity {
+	boundary := "=_" + b.random(12)
+	var buf bytes.Buffer
+	for i, part := range parts {
+		if i > 0 {
+			// The line break before a delimiter belongs to the delimiter.
+			buf.WriteString("\r\n")
+		}
+		buf.WriteString("--" + boundary + "\r\n")
+		for _, h := range part.header {
+			writeHeader(&buf, h.Name, h.Value)
+		}
+		buf.WriteString("\r\n")
+		buf.Write(part.body)
+	}
+	buf.WriteString("\r\n--" + boundary + "--\r\n")
+	return entity{
+		header: []Header{{Name: "Content-Type", Value: "multipart/" + subtype + `; boundary="` + boundary + `"`}},
+		body:   buf.Bytes(),
+	}
+}
+
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,179 @@
+// Package message builds RFC 5322 messages with MIME bodies for the
+// transports that take a full message rather than separate fields.
+package message
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/mail"
+	"strings"
+	"time"
+)
+
+// Message is the content of a message. Bcc recipients are not part of the
+// rendered message and are left to the transport.
+type Message struct {
+	From    mail.Address
+	To      []mail.Address
+	Cc      []mail.Address
+	ReplyTo []mail.Address
+	Subject string
+	// MessageID is the Message-ID including angle brackets, generated with
+	// Builder.MessageID when empty.
+	MessageID string
+	// Date is the Date header, the current time of the builder when zero.
+	Date time.Time
+	// Headers are added after the standard headers.
+	Headers []Header
+	// Text and HTML are the bodies. A message with both is rendered as
+	// multipart/alternative.
+	Text string
+	HTML string
+	// Attachments with a ContentID are related to the HTML body, the others
+	// make the message multipart/mixed.
+	Attachments []Attachment
+}
+
+// Header is an additional message header.
+type Header struct {
+	Name  string
+	Value string
+}
+
+// Attachment is a file attached to the message.
+type Attachment struct {
+	Filename    string
+	ContentType string
+	ContentID   string
+	Data        []byte
+}
+
+// Builder renders messages. Its zero value generates Message-IDs on the
+// domain of the sender and dates the messages with the current time.
+type Builder struct {
+	// Domain is the domain of generated Message-IDs.
+	Domain string
+	// Now returns the date of messages without one, time.Now when nil.
+	Now func() time.Time
+	// Rand is the source of Message-IDs and multipart boundaries,
+	// crypto/rand when nil.
+	Rand io.Reader
+}
+
+// MessageID returns a unique Message-ID, including angle brackets, on the
+// domain of the builder or else on the domain of from.
+func (b *Builder) MessageID(from string) string {
+	domain := b.Domain
+	if domain == "" {
+		domain = "localhost"
+		if i := strings.LastIndex(from, "@"); i >= 0 && i < len(from)-1 {
+			domain = strings.TrimSuffix(from[i+1:], ">")
+		}
+	}
+	return fmt.Sprintf("<%s@%s>", b.random(16), domain)
+}
+
+// Build renders m as an RFC 5322 message with CRLF line endings.
+func (b *Builder) Build(m *Message) ([]byte, error) {
+	for _, h := range m.Headers {
+		if !validHeaderName(h.Name) {
+			return nil, fmt.Errorf("invalid header name %q", h.Name)
+		}
+	}
+
+	messageID := m.MessageID
+	if messageID == "" {
+		messageID = b.MessageID(m.From.Address)
+	}
+	date := m.Date
+	if date.IsZero() {
+		date = b.now()
+	}
+
+	var buf bytes.Buffer
+	writeHeader(&buf, "From", FormatAddressList([]mail.Address{m.From}))
+	if len(m.To) > 0 {
+		writeHeader(&buf, "To", FormatAddressList(m.To))
+	}
+	if len(m.Cc) > 0 {
+		writeHeader(&buf, "Cc", FormatAddressList(m.Cc))
+	}
+	if len(m.ReplyTo) > 0 {
+		writeHeader(&buf, "Reply-To", FormatAddressList(m.ReplyTo))
+	}
+	writeHeader(&buf, "Subject", encodeWords(m.Subject))
+	writeHeader(&buf, "Date", date.Format(time.RFC1123Z))
+	writeHeader(&buf, "Message-ID", messageID)
+	for _, h := range m.Headers {
+		writeHeader(&buf, h.Name, encodeWords(h.Value))
+	}
+	writeHeader(&buf, "MIME-Version", "1.0")
+
+	body := b.body(m)
+	for _, h := range body.header {
+		writeHeader(&buf, h.Name, h.Value)
+	}
+	buf.WriteString("\r\n")
+	buf.Write(body.body)
+	return buf.Bytes(), nil
+}
+
+// body returns the MIME structure of the bodies and attachments of m.
+func (b *Builder) body(m *Message) entity {
+	var inline, attached []entity
+	for _, a := range m.Attachments {
+		if a.ContentID != "" && m.HTML != "" {
+			inline = append(inline, attachmentEntity(a))
+		} else {
+			attached = append(attached, attachmentEntity(a))
+		}
+	}
+
+	body := textEntity("text/plain; charset=utf-8", m.Text)
+	if m.HTML != "" {
+		html := textEntity("text/html; charset=utf-8", m.HTML)
+		if len(inline) > 0 {
+			html = b.multipart("related", append([]entity{html}, inline...))
+		}
+		body = b.multipart("alternative", []entity{body, html})
+	}
+	if len(attached) > 0 {
+		body = b.multipart("mixed", append([]entity{body}, attached...))
+	}
+	return body
+}
+
+func (b *Builder) now() time.Time {
+	if b.Now != nil {
+		return b.Now()
+	}
+	return time.Now()
+}
+
+// random returns n random bytes hex encoded.
+func (b *Builder) random(n int) string {
+	r := b.Rand
+	if r == nil {
+		r = rand.Reader
+	}
+	buf := make([]byte, n)
+	_, _ = io.ReadFull(r, buf)
+	return hex.EncodeToString(buf)
+}
+
+// validHeaderName reports whether name is a field name of printable ASCII
+// characters other than the colon.
+func validHeaderName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i := 0; i < len(name); i++ {
+		if name[i] < '!' || name[i] > '~' || name[i] == ':' {
+			return false
+		}
+	}
+	return true
+}
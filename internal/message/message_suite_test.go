@@ -0,0 +1,14 @@
+package message_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestMessage(t *testing.T) {
+	RegisterFailHandler(Fail)
+
+	RunSpecs(t, "Message Suite")
+}
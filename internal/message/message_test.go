@@ -0,0 +1,146 @@
+package message_test
+
+import (
+	"flag"
+	"io"
+	"mime"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/parhamds/Email-Operator/internal/message"
+)
+
+var update = flag.Bool("update", false, "update the golden files in testdata")
+
+// sequence is a deterministic random source returning 0, 1, 2, ...
+type sequence struct {
+	next byte
+}
+
+func (s *sequence) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = s.next
+		s.next++
+	}
+	return len(p), nil
+}
+
+var _ io.Reader = &sequence{}
+
+func newBuilder() *message.Builder {
+	return &message.Builder{
+		Domain: "mail.example.com",
+		Now:    func() time.Time { return time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC) },
+		Rand:   &sequence{},
+	}
+}
+
+var _ = Describe("Builder", func() {
+	DescribeTable("should build messages matching the golden files",
+		func(name string, m *message.Message) {
+			raw, err := newBuilder().Build(m)
+			Expect(err).NotTo(HaveOccurred())
+
+			golden := filepath.Join("testdata", name+".golden")
+			if *update {
+				Expect(os.WriteFile(golden, raw, 0o644)).To(Succeed())
+			}
+			want, err := os.ReadFile(golden)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(raw)).To(Equal(string(want)))
+
+			_, err = mail.ReadMessage(strings.NewReader(string(raw)))
+			Expect(err).NotTo(HaveOccurred())
+		},
+		Entry("plain text", "plain", &message.Message{
+			From:    mail.Address{Address: "sender@example.com"},
+			To:      []mail.Address{{Address: "recipient@example.com"}},
+			Subject: "Hello",
+			Text:    "Hello,\nthis is a plain text message.",
+		}),
+		Entry("non ASCII headers and body", "encoded", &message.Message{
+			From:    mail.Address{Name: "Jürgen Müller", Address: "juergen@example.com"},
+			To:      []mail.Address{{Name: "Zoë", Address: "zoe@example.com"}, {Address: "ops@example.com"}},
+			Cc:      []mail.Address{{Name: "Ops, Team", Address: "team@example.com"}},
+			ReplyTo: []mail.Address{{Name: "Support", Address: "support@example.com"}},
+			Subject: "Grüße aus München – Ihre Bestellung wurde versandt und ist bald bei Ihnen",
+			Headers: []message.Header{{Name: "X-Campaign", Value: "spring"}, {Name: "X-Note", Value: "日本語"}},
+			Text:    "Grüße,\nIhre Bestellung ist unterwegs.",
+		}),
+		Entry("HTML alternative", "alternative", &message.Message{
+			From:      mail.Address{Address: "sender@example.com"},
+			To:        []mail.Address{{Address: "recipient@example.com"}},
+			Subject:   "Release 1.4.2",
+			MessageID: "<release@example.com>",
+			Text:      "Release 1.4.2 is out.",
+			HTML:      "<p>Release <strong>1.4.2</strong> is out.</p>",
+		}),
+		Entry("inline and attached files", "attachments", &message.Message{
+			From:    mail.Address{Address: "sender@example.com"},
+			To:      []mail.Address{{Address: "recipient@example.com"}},
+			Subject: "Invoice",
+			Text:    "Your invoice is attached.",
+			HTML:    `<img src="cid:logo"><p>Your invoice is attached.</p>`,
+			Attachments: []message.Attachment{
+				{Filename: "invoice.pdf", ContentType: "application/pdf", Data: []byte(strings.Repeat("%PDF-1.7 ", 12))},
+				{Filename: "logo.png", ContentType: "image/png", ContentID: "logo", Data: []byte("\x89PNG\r\n\x1a\n")},
+			},
+		}),
+	)
+
+	It("should decode to the original headers", func() {
+		raw, err := newBuilder().Build(&message.Message{
+			From:    mail.Address{Name: "Jürgen Müller", Address: "juergen@example.com"},
+			To:      []mail.Address{{Address: "recipient@example.com"}},
+			Subject: "Grüße aus München – Ihre Bestellung wurde versandt und ist bald bei Ihnen",
+			Headers: []message.Header{{Name: "X-Note", Value: "line\r\nBcc: injected@example.com"}},
+			Text:    "Grüße",
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		msg, err := mail.ReadMessage(strings.NewReader(string(raw)))
+		Expect(err).NotTo(HaveOccurred())
+		decoder := new(mime.WordDecoder)
+		subject, err := decoder.DecodeHeader(msg.Header.Get("Subject"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(subject).To(Equal("Grüße aus München – Ihre Bestellung wurde versandt und ist bald bei Ihnen"))
+		from, err := msg.Header.AddressList("From")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(from).To(Equal([]*mail.Address{{Name: "Jürgen Müller", Address: "juergen@example.com"}}))
+		Expect(msg.Header).NotTo(HaveKey("Bcc"))
+		note, err := decoder.DecodeHeader(msg.Header.Get("X-Note"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(note).To(Equal("line\r\nBcc: injected@example.com"))
+
+		for _, line := range strings.Split(string(raw), "\r\n") {
+			Expect(len(line)).To(BeNumerically("<=", 78), line)
+		}
+	})
+
+	It("should reject invalid header names", func() {
+		_, err := newBuilder().Build(&message.Message{
+			From:    mail.Address{Address: "sender@example.com"},
+			Headers: []message.Header{{Name: "X Campaign", Value: "spring"}},
+		})
+		Expect(err).To(MatchError(`invalid header name "X Campaign"`))
+	})
+
+	It("should format address lists with encoded display names", func() {
+		Expect(message.FormatAddressList([]mail.Address{
+			{Address: "jane@example.com"},
+			{Name: "Zoë", Address: "zoe@example.com"},
+		})).To(Equal("jane@example.com, =?utf-8?q?Zo=C3=AB?= <zoe@example.com>"))
+	})
+
+	It("should generate Message-IDs on the configured domain", func() {
+		Expect(newBuilder().MessageID("sender@example.com")).To(Equal("<000102030405060708090a0b0c0d0e0f@mail.example.com>"))
+		Expect((&message.Builder{}).MessageID("Sender <sender@example.com>")).To(MatchRegexp(`^<[0-9a-f]{32}@example\.com>$`))
+		Expect((&message.Builder{}).MessageID("")).To(HaveSuffix("@localhost>"))
+	})
+})
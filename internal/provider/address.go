@@ -0,0 +1,30 @@
+package provider
+
+import (
+	"net/mail"
+)
+
+// ParseAddress parses a single address, either bare or with a display name
+// such as "Billing" <billing@example.com>.
+func ParseAddress(s string) (*mail.Address, error) {
+	return mail.ParseAddress(s)
+}
+
+// BareAddress returns the address of s without its display name. Strings
+// that do not parse are returned unchanged.
+func BareAddress(s string) string {
+	addr, err := mail.ParseAddress(s)
+	if err != nil {
+		return s
+	}
+	return addr.Address
+}
+
+// Addresses returns the bare addresses of addrs.
+func Addresses(addrs []mail.Address) []string {
+	out := make([]string, len(addrs))
+	for i, addr := range addrs {
+		out[i] = addr.Address
+	}
+	return out
+}
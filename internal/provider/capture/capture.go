@@ -0,0 +1,69 @@
+package capture
+
+import (
+	"context"
+	"time"
+
+	"github.com/parhamds/Email-Operator/internal/message"
+	"github.com/parhamds/Email-Operator/internal/provider"
+)
+
+// Name is the EmailSenderConfig provider value of this provider.
+const Name = "Capture"
+
+// DefaultStore holds the messages captured by the provider. It is served by
+// the manager when the capture endpoint is enabled.
+var DefaultStore = NewStore()
+
+func init() {
+	provider.Register(Name, New)
+}
+
+type captureProvider struct {
+	store   *Store
+	builder *message.Builder
+	now     func() time.Time
+}
+
+// New returns a provider keeping messages in memory instead of sending them.
+func New(cfg provider.Config) (provider.Provider, error) {
+	return &captureProvider{store: DefaultStore, builder: cfg.MessageBuilder(), now: time.Now}, nil
+}
+
+func (p *captureProvider) Validate() error {
+	return nil
+}
+
+func (p *captureProvider) Capabilities() provider.Capabilities {
+	return provider.Capabilities{Attachments: true, StoredTemplates: true, Headers: true, Tags: true, Metadata: true}
+}
+
+func (p *captureProvider) Send(ctx context.Context, msg *provider.Message) (*provider.Result, error) {
+	now := p.now()
+	messageID := p.builder.MessageID(msg.From)
+	raw, err := msg.Raw(p.builder, messageID, now)
+	if err != nil {
+		return nil, err
+	}
+	captured := Message{
+		MessageID: messageID,
+		From:      msg.From,
+		To:        provider.Addresses(msg.To),
+		Cc:        provider.Addresses(msg.Cc),
+		Bcc:       provider.Addresses(msg.Bcc),
+		Subject:   msg.Subject,
+		Body:      msg.Body,
+		HTML:      msg.HTML,
+		ReplyTo:   msg.ReplyTo,
+		Tags:      msg.Tags,
+		Metadata:  msg.Metadata,
+		Raw:       string(raw),
+		Time:      now,
+	}
+	if msg.Template != nil {
+		captured.Template = msg.Template.ID
+		captured.TemplateVariables = msg.Template.Variables
+	}
+	p.store.Add(captured)
+	return &provider.Result{MessageID: messageID}, nil
+}
@@ -0,0 +1,94 @@
+package capture_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/mail"
+	"net/url"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	parhamv1 "github.com/parhamds/Email-Operator/api/v1"
+	"github.com/parhamds/Email-Operator/internal/provider"
+	"github.com/parhamds/Email-Operator/internal/provider/capture"
+)
+
+var _ = Describe("Capture provider", func() {
+	var server *httptest.Server
+
+	BeforeEach(func() {
+		capture.DefaultStore.Clear()
+		server = httptest.NewServer(capture.Handler(capture.DefaultStore))
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	send := func(to string) string {
+		p, err := provider.New(provider.Config{
+			Spec: parhamv1.EmailSenderConfigSpec{Provider: capture.Name, SenderEmail: "sender@example.com"},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(p.Validate()).To(Succeed())
+
+		res, err := p.Send(context.Background(), &provider.Message{
+			From:    "sender@example.com",
+			To:      []mail.Address{{Address: to}},
+			Subject: "Test Subject",
+			Body:    "Test Body",
+		})
+		Expect(err).NotTo(HaveOccurred())
+		return res.MessageID
+	}
+
+	get := func(path string, v any) int {
+		res, err := http.Get(server.URL + path)
+		Expect(err).NotTo(HaveOccurred())
+		defer res.Body.Close()
+		Expect(json.NewDecoder(res.Body).Decode(v)).To(Succeed())
+		return res.StatusCode
+	}
+
+	It("should list captured messages", func() {
+		first := send("first@example.com")
+		second := send("second@example.com")
+
+		var messages []capture.Message
+		Expect(get("/api/messages", &messages)).To(Equal(http.StatusOK))
+		Expect(messages).To(HaveLen(2))
+		Expect(messages[0].MessageID).To(Equal(first))
+		Expect(messages[0].To).To(Equal([]string{"first@example.com"}))
+		Expect(messages[1].MessageID).To(Equal(second))
+		Expect(messages[1].Raw).To(ContainSubstring("Message-ID: " + second))
+	})
+
+	It("should get a message by Message-ID", func() {
+		id := send("recipient@example.com")
+
+		var msg capture.Message
+		Expect(get("/api/messages/"+url.PathEscape(id), &msg)).To(Equal(http.StatusOK))
+		Expect(msg.Subject).To(Equal("Test Subject"))
+
+		Expect(get("/api/messages/"+url.PathEscape(id[1:len(id)-1]), &msg)).To(Equal(http.StatusOK))
+
+		var notFound map[string]string
+		Expect(get("/api/messages/unknown", &notFound)).To(Equal(http.StatusNotFound))
+	})
+
+	It("should clear captured messages", func() {
+		send("recipient@example.com")
+
+		req, err := http.NewRequest(http.MethodDelete, server.URL+"/api/messages", nil)
+		Expect(err).NotTo(HaveOccurred())
+		res, err := http.DefaultClient.Do(req)
+		Expect(err).NotTo(HaveOccurred())
+		res.Body.Close()
+		Expect(res.StatusCode).To(Equal(http.StatusNoContent))
+
+		Expect(capture.DefaultStore.List()).To(BeEmpty())
+	})
+})
@@ -0,0 +1,37 @@
+package capture
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// Handler serves the messages of store:
+//
+//	GET    /api/messages       lists all messages
+//	GET    /api/messages/{id}  returns the message with the given Message-ID
+//	DELETE /api/messages       drops all messages
+func Handler(store *Store) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/messages", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, store.List())
+	})
+	mux.HandleFunc("GET /api/messages/{id}", func(w http.ResponseWriter, r *http.Request) {
+		msg, ok := store.Get(r.PathValue("id"))
+		if !ok {
+			writeJSON(w, http.StatusNotFound, map[string]string{"error": "message not found"})
+			return
+		}
+		writeJSON(w, http.StatusOK, msg)
+	})
+	mux.HandleFunc("DELETE /api/messages", func(w http.ResponseWriter, r *http.Request) {
+		store.Clear()
+		w.WriteHeader(http.StatusNoContent)
+	})
+	return mux
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,49 @@
+package capture
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// Server serves a Store over HTTP. It implements manager.Runnable so it can
+// be added to the controller manager.
+type Server struct {
+	Addr  string
+	Store *Store
+}
+
+// Start serves the store until ctx is done.
+func (s *Server) Start(ctx context.Context) error {
+	srv := &http.Server{
+		Addr:              s.Addr,
+		Handler:           Handler(s.Store),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		log.FromContext(ctx).Info("serving captured messages", "address", s.Addr)
+		errCh <- srv.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := srv.Shutdown(shutdownCtx); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			return err
+		}
+		return nil
+	}
+}
+
+// NeedLeaderElection reports that every replica serves its own messages.
+func (s *Server) NeedLeaderElection() bool {
+	return false
+}
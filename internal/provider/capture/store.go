@@ -0,0 +1,74 @@
+package capture
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// Message is a captured message.
+type Message struct {
+	MessageID string            `json:"messageId"`
+	From      string            `json:"from"`
+	To        []string          `json:"to"`
+	Cc        []string          `json:"cc,omitempty"`
+	Bcc       []string          `json:"bcc,omitempty"`
+	Subject   string            `json:"subject"`
+	Body      string            `json:"body"`
+	HTML      string            `json:"html,omitempty"`
+	ReplyTo   string            `json:"replyTo,omitempty"`
+	Tags      []string          `json:"tags,omitempty"`
+	Metadata  map[string]string `json:"metadata,omitempty"`
+	// Template and TemplateVariables record the stored template the
+	// message references.
+	Template          string            `json:"template,omitempty"`
+	TemplateVariables map[string]string `json:"templateVariables,omitempty"`
+	Raw               string            `json:"raw"`
+	Time              time.Time         `json:"time"`
+}
+
+// Store keeps captured messages in memory, oldest first.
+type Store struct {
+	mu       sync.RWMutex
+	messages []Message
+}
+
+// NewStore returns an empty Store.
+func NewStore() *Store {
+	return &Store{}
+}
+
+// Add stores msg.
+func (s *Store) Add(msg Message) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.messages = append(s.messages, msg)
+}
+
+// List returns a copy of all captured messages.
+func (s *Store) List() []Message {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append([]Message{}, s.messages...)
+}
+
+// Get returns the message with the given Message-ID, with or without angle brackets.
+func (s *Store) Get(messageID string) (Message, bool) {
+	messageID = strings.Trim(messageID, "<>")
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, msg := range s.messages {
+		if strings.Trim(msg.MessageID, "<>") == messageID {
+			return msg, true
+		}
+	}
+	return Message{}, false
+}
+
+// Clear drops all captured messages.
+func (s *Store) Clear() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.messages = nil
+}
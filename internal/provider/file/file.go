@@ -0,0 +1,144 @@
+package file
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/parhamds/Email-Operator/internal/message"
+	"github.com/parhamds/Email-Operator/internal/provider"
+)
+
+// Name is the EmailSenderConfig provider value of this provider.
+const Name = "File"
+
+// Output formats supported by the provider.
+const (
+	FormatEML  = "eml"
+	FormatMbox = "mbox"
+)
+
+// MboxName is the name of the mbox file in the configured directory.
+const MboxName = "messages.mbox"
+
+// mboxMu serializes appends to mbox files.
+var mboxMu sync.Mutex
+
+func init() {
+	provider.Register(Name, New)
+}
+
+type fileProvider struct {
+	directory string
+	format    string
+	builder   *message.Builder
+	now       func() time.Time
+}
+
+// New returns a provider writing messages to a directory instead of sending them.
+func New(cfg provider.Config) (provider.Provider, error) {
+	if cfg.Spec.File == nil {
+		return nil, errors.New("file configuration is required for the File provider")
+	}
+	p := &fileProvider{
+		directory: cfg.Spec.File.Directory,
+		format:    cfg.Spec.File.Format,
+		builder:   cfg.MessageBuilder(),
+		now:       time.Now,
+	}
+	if p.format == "" {
+		p.format = FormatEML
+	}
+	return p, nil
+}
+
+func (p *fileProvider) Validate() error {
+	if p.format != FormatEML && p.format != FormatMbox {
+		return fmt.Errorf("unsupported file format %q", p.format)
+	}
+	info, err := os.Stat(p.directory)
+	if err != nil {
+		return fmt.Errorf("unable to access directory: %v", err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("%s is not a directory", p.directory)
+	}
+	return nil
+}
+
+func (p *fileProvider) Capabilities() provider.Capabilities {
+	return provider.Capabilities{Attachments: true, Headers: true}
+}
+
+func (p *fileProvider) Send(ctx context.Context, msg *provider.Message) (*provider.Result, error) {
+	now := p.now()
+	messageID := p.builder.MessageID(msg.From)
+	raw, err := msg.Raw(p.builder, messageID, now)
+	if err != nil {
+		return nil, err
+	}
+
+	if p.format == FormatMbox {
+		err = p.appendMbox(msg.From, raw, now)
+	} else {
+		err = p.writeEML(messageID, raw)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &provider.Result{MessageID: messageID}, nil
+}
+
+func (p *fileProvider) writeEML(messageID string, raw []byte) error {
+	name := strings.Trim(messageID, "<>")
+	name = strings.Map(func(r rune) rune {
+		if r == '/' || r == '\\' {
+			return '_'
+		}
+		return r
+	}, name)
+	return os.WriteFile(filepath.Join(p.directory, name+".eml"), raw, 0o644)
+}
+
+// appendMbox appends raw to the mbox file using the mboxrd format.
+func (p *fileProvider) appendMbox(from string, raw []byte, now time.Time) error {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From %s %s\n", envelopeSender(from), now.UTC().Format(time.ANSIC))
+	for _, line := range strings.Split(strings.TrimSuffix(strings.ReplaceAll(string(raw), "\r\n", "\n"), "\n"), "\n") {
+		if strings.HasPrefix(strings.TrimLeft(line, ">"), "From ") {
+			buf.WriteString(">")
+		}
+		buf.WriteString(line + "\n")
+	}
+	buf.WriteString("\n")
+
+	mboxMu.Lock()
+	defer mboxMu.Unlock()
+
+	f, err := os.OpenFile(filepath.Join(p.directory, MboxName), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(buf.Bytes()); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// envelopeSender returns the bare address of from for the mbox From_ line.
+func envelopeSender(from string) string {
+	if i := strings.LastIndex(from, "<"); i >= 0 {
+		from = strings.TrimSuffix(from[i+1:], ">")
+	}
+	if from == "" {
+		return "MAILER-DAEMON"
+	}
+	return from
+}
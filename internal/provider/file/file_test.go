@@ -0,0 +1,83 @@
+package file_test
+
+import (
+	"context"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	parhamv1 "github.com/parhamds/Email-Operator/api/v1"
+	"github.com/parhamds/Email-Operator/internal/provider"
+	"github.com/parhamds/Email-Operator/internal/provider/file"
+)
+
+var _ = Describe("File provider", func() {
+	var dir string
+
+	BeforeEach(func() {
+		dir = GinkgoT().TempDir()
+	})
+
+	newProvider := func(format string) provider.Provider {
+		p, err := file.New(provider.Config{
+			Spec: parhamv1.EmailSenderConfigSpec{
+				Provider:    file.Name,
+				SenderEmail: "sender@example.com",
+				File:        &parhamv1.FileConfig{Directory: dir, Format: format},
+			},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(p.Validate()).To(Succeed())
+		return p
+	}
+
+	msg := &provider.Message{
+		From:    "sender@example.com",
+		To:      []mail.Address{{Address: "recipient@example.com"}},
+		Subject: "Test Subject",
+		Body:    "Test Body\nFrom the operator",
+	}
+
+	It("should write one .eml file per message", func() {
+		res, err := newProvider("").Send(context.Background(), msg)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(res.MessageID).To(MatchRegexp(`^<[0-9a-f]{32}@example\.com>$`))
+
+		data, err := os.ReadFile(filepath.Join(dir, strings.Trim(res.MessageID, "<>")+".eml"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(data)).To(ContainSubstring("Message-ID: " + res.MessageID + "\r\n"))
+		Expect(string(data)).To(ContainSubstring("Subject: Test Subject\r\n"))
+	})
+
+	It("should append messages to an mbox file", func() {
+		p := newProvider(file.FormatMbox)
+		first, err := p.Send(context.Background(), msg)
+		Expect(err).NotTo(HaveOccurred())
+		second, err := p.Send(context.Background(), msg)
+		Expect(err).NotTo(HaveOccurred())
+
+		data, err := os.ReadFile(filepath.Join(dir, file.MboxName))
+		Expect(err).NotTo(HaveOccurred())
+		mbox := string(data)
+		Expect(strings.Count(mbox, "\nFrom sender@example.com ")).To(Equal(1))
+		Expect(mbox).To(HavePrefix("From sender@example.com "))
+		Expect(mbox).To(ContainSubstring("Message-ID: " + first.MessageID + "\n"))
+		Expect(mbox).To(ContainSubstring("Message-ID: " + second.MessageID + "\n"))
+		Expect(mbox).To(ContainSubstring("\n>From the operator\n"))
+		Expect(mbox).NotTo(ContainSubstring("\r\n"))
+	})
+
+	It("should fail validation for a missing directory", func() {
+		p, err := file.New(provider.Config{
+			Spec: parhamv1.EmailSenderConfigSpec{
+				File: &parhamv1.FileConfig{Directory: filepath.Join(dir, "missing")},
+			},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(p.Validate()).To(MatchError(ContainSubstring("unable to access directory")))
+	})
+})
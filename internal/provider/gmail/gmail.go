@@ -0,0 +1,225 @@
+package gmail
+
+import (
+	"bytes"
+	"context"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/parhamds/Email-Operator/internal/message"
+	"github.com/parhamds/Email-Operator/internal/provider"
+)
+
+// Name is the EmailSenderConfig provider value of this provider.
+const Name = "Gmail"
+
+const (
+	// DefaultTokenURL is the token endpoint used when neither the config nor
+	// the service account key sets one.
+	DefaultTokenURL = "https://oauth2.googleapis.com/token"
+	// DefaultBaseURL is the Gmail API base URL used when none is configured.
+	DefaultBaseURL = "https://gmail.googleapis.com"
+
+	sendScope = "https://www.googleapis.com/auth/gmail.send"
+)
+
+// tokens caches delegated access tokens across sends.
+var tokens = provider.NewTokenCache()
+
+func init() {
+	provider.Register(Name, New)
+}
+
+type gmail struct {
+	baseURL    string
+	tokenURL   string
+	sender     string
+	account    *serviceAccountKey
+	privateKey *rsa.PrivateKey
+	client     *http.Client
+	builder    *message.Builder
+	timeout    time.Duration
+}
+
+// New returns a provider sending through the Gmail API as the sender, using a
+// service account with domain-wide delegation.
+func New(cfg provider.Config) (provider.Provider, error) {
+	client, err := cfg.Transport.HTTPClient()
+	if err != nil {
+		return nil, err
+	}
+	keyData, err := cfg.SecretValue("serviceAccountKey")
+	if err != nil {
+		return nil, err
+	}
+	account, privateKey, err := parseServiceAccountKey([]byte(keyData))
+	if err != nil {
+		return nil, err
+	}
+
+	p := &gmail{
+		baseURL:    DefaultBaseURL,
+		tokenURL:   account.TokenURI,
+		sender:     provider.BareAddress(cfg.Spec.SenderEmail),
+		account:    account,
+		privateKey: privateKey,
+		client:     client,
+		builder:    cfg.MessageBuilder(),
+		timeout:    cfg.Timeout(),
+	}
+	if p.tokenURL == "" {
+		p.tokenURL = DefaultTokenURL
+	}
+	if cfg.Spec.Gmail != nil {
+		if cfg.Spec.Gmail.TokenURL != "" {
+			p.tokenURL = cfg.Spec.Gmail.TokenURL
+		}
+		if cfg.Spec.Gmail.BaseURL != "" {
+			p.baseURL = strings.TrimSuffix(cfg.Spec.Gmail.BaseURL, "/")
+		}
+	}
+	return p, nil
+}
+
+func (p *gmail) Validate() error {
+	if p.sender == "" {
+		return errors.New("sender email must not be empty")
+	}
+	return nil
+}
+
+func (p *gmail) Capabilities() provider.Capabilities {
+	return provider.Capabilities{Attachments: true, Headers: true}
+}
+
+type sendRequest struct {
+	Raw string `json:"raw"`
+}
+
+type sendResponse struct {
+	ID       string `json:"id"`
+	ThreadID string `json:"threadId"`
+}
+
+type errorResponse struct {
+	Error struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+		Status  string `json:"status"`
+	} `json:"error"`
+}
+
+func (p *gmail) Send(ctx context.Context, msg *provider.Message) (*provider.Result, error) {
+	sendCtx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+
+	accessToken, err := tokens.Get(sendCtx, p.tokenKey(), p.fetchToken)
+	if err != nil {
+		return nil, err
+	}
+
+	raw, err := msg.Raw(p.builder, p.builder.MessageID(msg.From), time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if len(msg.Bcc) > 0 {
+		// Gmail reads Bcc recipients from the header and strips it before delivery.
+		raw = append([]byte("Bcc: "+message.FormatAddressList(msg.Bcc)+"\r\n"), raw...)
+	}
+	payload, err := json.Marshal(sendRequest{Raw: base64.URLEncoding.EncodeToString(raw)})
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint := p.baseURL + "/gmail/v1/users/me/messages/send"
+	req, err := http.NewRequestWithContext(sendCtx, http.MethodPost, endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := p.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(res.Body, 64<<10))
+	if res.StatusCode != http.StatusOK {
+		if res.StatusCode == http.StatusUnauthorized {
+			tokens.Invalidate(p.tokenKey())
+		}
+		var errRes errorResponse
+		if err := json.Unmarshal(body, &errRes); err == nil && errRes.Error.Message != "" {
+			return nil, fmt.Errorf("error response from Gmail: %s: %s", res.Status, errRes.Error.Message)
+		}
+		return nil, fmt.Errorf("error response from Gmail: %s", res.Status)
+	}
+
+	var out sendResponse
+	if err := json.Unmarshal(body, &out); err != nil {
+		return nil, fmt.Errorf("invalid response from Gmail: %v", err)
+	}
+	return &provider.Result{MessageID: out.ID, ThreadID: out.ThreadID}, nil
+}
+
+func (p *gmail) tokenKey() string {
+	return strings.Join([]string{p.tokenURL, p.account.ClientEmail, p.account.PrivateKeyID, p.sender}, "\x00")
+}
+
+type tokenResponse struct {
+	AccessToken      string `json:"access_token"`
+	ExpiresIn        int64  `json:"expires_in"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+// fetchToken exchanges a signed JWT impersonating the sender for an access token.
+func (p *gmail) fetchToken(ctx context.Context, now time.Time) (provider.Token, error) {
+	assertion, err := signJWT(p.privateKey, p.account.PrivateKeyID, newClaims(p.account, p.sender, p.tokenURL, now))
+	if err != nil {
+		return provider.Token{}, err
+	}
+
+	form := url.Values{
+		"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
+		"assertion":  {assertion},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return provider.Token{}, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	res, err := p.client.Do(req)
+	if err != nil {
+		return provider.Token{}, err
+	}
+	defer res.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(res.Body, 64<<10))
+	var out tokenResponse
+	_ = json.Unmarshal(body, &out)
+	if res.StatusCode != http.StatusOK {
+		if out.Error != "" {
+			return provider.Token{}, fmt.Errorf("unable to get access token: %s: %s: %s", res.Status, out.Error, out.ErrorDescription)
+		}
+		return provider.Token{}, fmt.Errorf("unable to get access token: %s", res.Status)
+	}
+	if out.AccessToken == "" {
+		return provider.Token{}, errors.New("unable to get access token: empty access_token in response")
+	}
+	return provider.Token{
+		AccessToken: out.AccessToken,
+		Expiry:      now.Add(time.Duration(out.ExpiresIn) * time.Second),
+	}, nil
+}
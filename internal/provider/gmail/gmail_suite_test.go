@@ -0,0 +1,14 @@
+package gmail
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestGmail(t *testing.T) {
+	RegisterFailHandler(Fail)
+
+	RunSpecs(t, "Gmail Provider Suite")
+}
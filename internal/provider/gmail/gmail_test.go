@@ -0,0 +1,166 @@
+package gmail
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/mail"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	parhamv1 "github.com/parhamds/Email-Operator/api/v1"
+	"github.com/parhamds/Email-Operator/internal/provider"
+)
+
+// verifyJWT checks the RS256 signature of assertion and returns its claims.
+func verifyJWT(pub *rsa.PublicKey, assertion string) jwtClaims {
+	parts := strings.Split(assertion, ".")
+	Expect(parts).To(HaveLen(3))
+
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	Expect(err).NotTo(HaveOccurred())
+	sum := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	Expect(rsa.VerifyPKCS1v15(pub, crypto.SHA256, sum[:], sig)).To(Succeed())
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	Expect(err).NotTo(HaveOccurred())
+	var claims jwtClaims
+	Expect(json.Unmarshal(payload, &claims)).To(Succeed())
+	return claims
+}
+
+var _ = Describe("Gmail provider", func() {
+	var (
+		key        *rsa.PrivateKey
+		keyJSON    []byte
+		tokenSrv   *httptest.Server
+		api        *httptest.Server
+		tokenCalls int
+		claims     jwtClaims
+		raw        string
+		apiStatus  int
+	)
+
+	BeforeEach(func() {
+		var err error
+		key, err = rsa.GenerateKey(rand.Reader, 2048)
+		Expect(err).NotTo(HaveOccurred())
+		der, err := x509.MarshalPKCS8PrivateKey(key)
+		Expect(err).NotTo(HaveOccurred())
+		keyJSON, err = json.Marshal(map[string]string{
+			"type":           "service_account",
+			"client_email":   "mailer@project.iam.gserviceaccount.com",
+			"private_key_id": "key-1",
+			"private_key":    string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})),
+			"token_uri":      "https://oauth2.googleapis.com/token",
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		tokens = provider.NewTokenCache()
+		tokenCalls = 0
+		apiStatus = http.StatusOK
+
+		tokenSrv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			Expect(r.ParseForm()).To(Succeed())
+			Expect(r.PostForm.Get("grant_type")).To(Equal("urn:ietf:params:oauth:grant-type:jwt-bearer"))
+			claims = verifyJWT(&key.PublicKey, r.PostForm.Get("assertion"))
+			tokenCalls++
+			_, _ = fmt.Fprintf(w, `{"access_token":"token-%d","expires_in":3600,"token_type":"Bearer"}`, tokenCalls)
+		}))
+		api = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			Expect(r.URL.Path).To(Equal("/gmail/v1/users/me/messages/send"))
+			Expect(r.Header.Get("Authorization")).To(Equal(fmt.Sprintf("Bearer token-%d", tokenCalls)))
+			var req sendRequest
+			Expect(json.NewDecoder(r.Body).Decode(&req)).To(Succeed())
+			decoded, err := base64.URLEncoding.DecodeString(req.Raw)
+			Expect(err).NotTo(HaveOccurred())
+			raw = string(decoded)
+
+			w.WriteHeader(apiStatus)
+			if apiStatus != http.StatusOK {
+				_, _ = w.Write([]byte(`{"error":{"code":400,"message":"Precondition check failed.","status":"FAILED_PRECONDITION"}}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"id":"18c2f5a1b2c3d4e5","threadId":"18c2f5a1b2c3d4e0","labelIds":["SENT"]}`))
+		}))
+	})
+
+	AfterEach(func() {
+		tokenSrv.Close()
+		api.Close()
+	})
+
+	newProvider := func() provider.Provider {
+		p, err := New(provider.Config{
+			Spec: parhamv1.EmailSenderConfigSpec{
+				Provider:    Name,
+				SenderEmail: "sender@example.com",
+				Gmail:       &parhamv1.GmailConfig{TokenURL: tokenSrv.URL, BaseURL: api.URL},
+			},
+			SecretName: "gmail-service-account",
+			Secret:     map[string][]byte{"serviceAccountKey": keyJSON},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(p.Validate()).To(Succeed())
+		return p
+	}
+
+	msg := &provider.Message{
+		From:    "sender@example.com",
+		To:      []mail.Address{{Address: "recipient@example.com"}},
+		Subject: "Test Subject",
+		Body:    "Test Body",
+	}
+
+	It("should impersonate the sender and record the message and thread ids", func() {
+		res, err := newProvider().Send(context.Background(), msg)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(res.MessageID).To(Equal("18c2f5a1b2c3d4e5"))
+		Expect(res.ThreadID).To(Equal("18c2f5a1b2c3d4e0"))
+
+		Expect(claims.Issuer).To(Equal("mailer@project.iam.gserviceaccount.com"))
+		Expect(claims.Subject).To(Equal("sender@example.com"))
+		Expect(claims.Audience).To(Equal(tokenSrv.URL))
+		Expect(claims.Scope).To(Equal(sendScope))
+		Expect(claims.Expiry - claims.IssuedAt).To(BeEquivalentTo(3600))
+
+		Expect(raw).To(ContainSubstring("From: sender@example.com\r\n"))
+		Expect(raw).To(ContainSubstring("To: recipient@example.com\r\n"))
+		Expect(raw).To(ContainSubstring("Subject: Test Subject\r\n"))
+		Expect(raw).To(HaveSuffix("\r\n\r\nTest Body\r\n"))
+	})
+
+	It("should reuse the access token across sends", func() {
+		for i := 0; i < 2; i++ {
+			_, err := newProvider().Send(context.Background(), msg)
+			Expect(err).NotTo(HaveOccurred())
+		}
+		Expect(tokenCalls).To(Equal(1))
+	})
+
+	It("should surface Gmail errors", func() {
+		apiStatus = http.StatusBadRequest
+
+		_, err := newProvider().Send(context.Background(), msg)
+		Expect(err).To(MatchError("error response from Gmail: 400 Bad Request: Precondition check failed."))
+	})
+
+	It("should reject an invalid service account key", func() {
+		_, err := New(provider.Config{
+			SecretName: "gmail-service-account",
+			Secret:     map[string][]byte{"serviceAccountKey": []byte(`{"client_email":"a@b.c","private_key":"nope"}`)},
+		})
+		Expect(err).To(MatchError("invalid service account key: private_key is not PEM encoded"))
+	})
+})
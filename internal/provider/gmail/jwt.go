@@ -0,0 +1,91 @@
+package gmail
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// serviceAccountKey is the subset of a Google service account JSON key used
+// by the provider.
+type serviceAccountKey struct {
+	ClientEmail  string `json:"client_email"`
+	PrivateKeyID string `json:"private_key_id"`
+	PrivateKey   string `json:"private_key"`
+	TokenURI     string `json:"token_uri"`
+}
+
+func parseServiceAccountKey(data []byte) (*serviceAccountKey, *rsa.PrivateKey, error) {
+	var key serviceAccountKey
+	if err := json.Unmarshal(data, &key); err != nil {
+		return nil, nil, fmt.Errorf("invalid service account key: %v", err)
+	}
+	if key.ClientEmail == "" {
+		return nil, nil, errors.New("invalid service account key: missing client_email")
+	}
+
+	block, _ := pem.Decode([]byte(key.PrivateKey))
+	if block == nil {
+		return nil, nil, errors.New("invalid service account key: private_key is not PEM encoded")
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		if parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes); err != nil {
+			return nil, nil, fmt.Errorf("invalid service account key: %v", err)
+		}
+	}
+	rsaKey, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, nil, errors.New("invalid service account key: private_key is not an RSA key")
+	}
+	return &key, rsaKey, nil
+}
+
+type jwtClaims struct {
+	Issuer   string `json:"iss"`
+	Scope    string `json:"scope"`
+	Audience string `json:"aud"`
+	Subject  string `json:"sub,omitempty"`
+	IssuedAt int64  `json:"iat"`
+	Expiry   int64  `json:"exp"`
+}
+
+// signJWT returns an RS256 signed JWT assertion for claims.
+func signJWT(key *rsa.PrivateKey, keyID string, claims jwtClaims) (string, error) {
+	header, err := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT", "kid": keyID})
+	if err != nil {
+		return "", err
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+
+	enc := base64.RawURLEncoding
+	unsigned := enc.EncodeToString(header) + "." + enc.EncodeToString(payload)
+	sum := sha256.Sum256([]byte(unsigned))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, sum[:])
+	if err != nil {
+		return "", err
+	}
+	return unsigned + "." + enc.EncodeToString(signature), nil
+}
+
+func newClaims(key *serviceAccountKey, subject, audience string, now time.Time) jwtClaims {
+	return jwtClaims{
+		Issuer:   key.ClientEmail,
+		Scope:    sendScope,
+		Audience: audience,
+		Subject:  subject,
+		IssuedAt: now.Unix(),
+		Expiry:   now.Add(time.Hour).Unix(),
+	}
+}
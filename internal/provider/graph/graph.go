@@ -0,0 +1,229 @@
+package graph
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/mail"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/parhamds/Email-Operator/internal/provider"
+)
+
+// Name is the EmailSenderConfig provider value of this provider.
+const Name = "Graph"
+
+const (
+	// DefaultAuthorityURL is the OAuth2 authority used when none is configured.
+	DefaultAuthorityURL = "https://login.microsoftonline.com"
+	// DefaultBaseURL is the Graph API base URL used when none is configured.
+	DefaultBaseURL = "https://graph.microsoft.com"
+)
+
+func init() {
+	provider.Register(Name, New)
+}
+
+type graph struct {
+	baseURL  string
+	tenantID string
+	sender   string
+	client   *http.Client
+	source   *tokenSource
+	timeout  time.Duration
+}
+
+// New returns a provider sending through the Microsoft Graph sendMail API.
+func New(cfg provider.Config) (provider.Provider, error) {
+	client, err := cfg.Transport.HTTPClient()
+	if err != nil {
+		return nil, err
+	}
+	authorityURL, baseURL := DefaultAuthorityURL, DefaultBaseURL
+	if cfg.Spec.Graph != nil {
+		if cfg.Spec.Graph.AuthorityURL != "" {
+			authorityURL = cfg.Spec.Graph.AuthorityURL
+		}
+		if cfg.Spec.Graph.BaseURL != "" {
+			baseURL = cfg.Spec.Graph.BaseURL
+		}
+	}
+	authorityURL = strings.TrimSuffix(authorityURL, "/")
+	baseURL = strings.TrimSuffix(baseURL, "/")
+
+	p := &graph{
+		baseURL: baseURL,
+		sender:  provider.BareAddress(cfg.Spec.SenderEmail),
+		client:  client,
+		timeout: cfg.Timeout(),
+	}
+	source := &tokenSource{client: p.client, scope: baseURL + "/.default"}
+
+	if p.tenantID, err = cfg.SecretValue("tenantId"); err != nil {
+		return nil, err
+	}
+	if source.clientID, err = cfg.SecretValue("clientId"); err != nil {
+		return nil, err
+	}
+	if source.clientSecret, err = cfg.SecretValue("clientSecret"); err != nil {
+		return nil, err
+	}
+	source.tokenURL = fmt.Sprintf("%s/%s/oauth2/v2.0/token", authorityURL, url.PathEscape(p.tenantID))
+	p.source = source
+	return p, nil
+}
+
+func (p *graph) Validate() error {
+	if p.tenantID == "" || p.source.clientID == "" || p.source.clientSecret == "" {
+		return errors.New("tenant ID, client ID and client secret must not be empty")
+	}
+	return nil
+}
+
+func (p *graph) Capabilities() provider.Capabilities {
+	return provider.Capabilities{Attachments: true, Tags: true}
+}
+
+type emailAddress struct {
+	Address string `json:"address"`
+	Name    string `json:"name,omitempty"`
+}
+
+type recipient struct {
+	EmailAddress emailAddress `json:"emailAddress"`
+}
+
+func recipients(addrs []mail.Address) []recipient {
+	out := make([]recipient, len(addrs))
+	for i, addr := range addrs {
+		out[i] = recipient{EmailAddress: emailAddress{Address: addr.Address, Name: addr.Name}}
+	}
+	return out
+}
+
+type itemBody struct {
+	ContentType string `json:"contentType"`
+	Content     string `json:"content"`
+}
+
+type message struct {
+	Subject       string           `json:"subject"`
+	Body          itemBody         `json:"body"`
+	ToRecipients  []recipient      `json:"toRecipients"`
+	CcRecipients  []recipient      `json:"ccRecipients,omitempty"`
+	BccRecipients []recipient      `json:"bccRecipients,omitempty"`
+	Attachments   []fileAttachment `json:"attachments,omitempty"`
+	ReplyTo       []recipient      `json:"replyTo,omitempty"`
+	Categories    []string         `json:"categories,omitempty"`
+}
+
+type fileAttachment struct {
+	ODataType    string `json:"@odata.type"`
+	Name         string `json:"name"`
+	ContentType  string `json:"contentType,omitempty"`
+	ContentBytes []byte `json:"contentBytes"`
+	IsInline     bool   `json:"isInline"`
+	ContentID    string `json:"contentId,omitempty"`
+}
+
+func attachments(in []provider.Attachment) []fileAttachment {
+	var out []fileAttachment
+	for _, a := range in {
+		out = append(out, fileAttachment{
+			ODataType:    "#microsoft.graph.fileAttachment",
+			Name:         a.Filename,
+			ContentType:  a.ContentType,
+			ContentBytes: a.Data,
+			IsInline:     a.Inline(),
+			ContentID:    a.ContentID,
+		})
+	}
+	return out
+}
+
+type sendMailRequest struct {
+	Message         message `json:"message"`
+	SaveToSentItems bool    `json:"saveToSentItems"`
+}
+
+type errorResponse struct {
+	Error struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+func (p *graph) Send(ctx context.Context, msg *provider.Message) (*provider.Result, error) {
+	sendCtx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+
+	// Graph messages carry a single body, so HTML is sent in place of the text.
+	body := itemBody{ContentType: "Text", Content: msg.Body}
+	if msg.HTML != "" {
+		body = itemBody{ContentType: "HTML", Content: msg.HTML}
+	}
+	m := message{
+		Subject:       msg.Subject,
+		Body:          body,
+		ToRecipients:  recipients(msg.To),
+		CcRecipients:  recipients(msg.Cc),
+		BccRecipients: recipients(msg.Bcc),
+		Attachments:   attachments(msg.Attachments),
+		Categories:    msg.Tags,
+	}
+	if addr, err := provider.ParseAddress(msg.ReplyTo); msg.ReplyTo != "" && err == nil {
+		m.ReplyTo = recipients([]mail.Address{*addr})
+	}
+	payload, err := json.Marshal(sendMailRequest{Message: m, SaveToSentItems: true})
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := p.sendMail(sendCtx, payload)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode == http.StatusUnauthorized {
+		// The cached token may have been revoked, retry once with a fresh one.
+		res.Body.Close()
+		tokens.Invalidate(p.source.key())
+		if res, err = p.sendMail(sendCtx, payload); err != nil {
+			return nil, err
+		}
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusAccepted {
+		body, _ := io.ReadAll(io.LimitReader(res.Body, 64<<10))
+		var errRes errorResponse
+		if err := json.Unmarshal(body, &errRes); err == nil && errRes.Error.Code != "" {
+			return nil, fmt.Errorf("error response from Graph: %s: %s: %s", res.Status, errRes.Error.Code, errRes.Error.Message)
+		}
+		return nil, fmt.Errorf("error response from Graph: %s", res.Status)
+	}
+
+	// sendMail does not return the message, the request id identifies the call.
+	return &provider.Result{MessageID: res.Header.Get("request-id")}, nil
+}
+
+func (p *graph) sendMail(ctx context.Context, payload []byte) (*http.Response, error) {
+	accessToken, err := p.source.token(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint := fmt.Sprintf("%s/v1.0/users/%s/sendMail", p.baseURL, url.PathEscape(p.sender))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	req.Header.Set("Content-Type", "application/json")
+	return p.client.Do(req)
+}
@@ -0,0 +1,149 @@
+package graph
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/mail"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	parhamv1 "github.com/parhamds/Email-Operator/api/v1"
+	"github.com/parhamds/Email-Operator/internal/provider"
+)
+
+var _ = Describe("Graph provider", func() {
+	var (
+		authority   *httptest.Server
+		api         *httptest.Server
+		tokenCalls  int
+		graphCalls  int
+		now         time.Time
+		received    sendMailRequest
+		graphStatus func(call int) int
+	)
+
+	BeforeEach(func() {
+		tokenCalls, graphCalls = 0, 0
+		now = time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+		tokens = provider.NewTokenCache()
+		tokens.Now = func() time.Time { return now }
+		graphStatus = func(int) int { return http.StatusAccepted }
+
+		authority = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			Expect(r.URL.Path).To(Equal("/tenant-id/oauth2/v2.0/token"))
+			Expect(r.ParseForm()).To(Succeed())
+			Expect(r.PostForm.Get("grant_type")).To(Equal("client_credentials"))
+			Expect(r.PostForm.Get("client_id")).To(Equal("client-id"))
+			if r.PostForm.Get("client_secret") != "client-secret" {
+				w.WriteHeader(http.StatusUnauthorized)
+				_, _ = w.Write([]byte(`{"error":"invalid_client","error_description":"AADSTS7000215: Invalid client secret provided."}`))
+				return
+			}
+			Expect(r.PostForm.Get("scope")).To(Equal(api.URL + "/.default"))
+			tokenCalls++
+			_, _ = fmt.Fprintf(w, `{"token_type":"Bearer","expires_in":3599,"access_token":"token-%d"}`, tokenCalls)
+		}))
+		api = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			Expect(r.URL.Path).To(Equal("/v1.0/users/sender@example.com/sendMail"))
+			graphCalls++
+			Expect(r.Header.Get("Authorization")).To(Equal(fmt.Sprintf("Bearer token-%d", tokenCalls)))
+			Expect(json.NewDecoder(r.Body).Decode(&received)).To(Succeed())
+			status := graphStatus(graphCalls)
+			if status == http.StatusAccepted {
+				w.Header().Set("request-id", fmt.Sprintf("request-%d", graphCalls))
+			} else {
+				w.WriteHeader(status)
+				_, _ = w.Write([]byte(`{"error":{"code":"ErrorAccessDenied","message":"Access is denied."}}`))
+				return
+			}
+			w.WriteHeader(status)
+		}))
+	})
+
+	AfterEach(func() {
+		authority.Close()
+		api.Close()
+	})
+
+	newProvider := func(clientSecret string) provider.Provider {
+		p, err := New(provider.Config{
+			Spec: parhamv1.EmailSenderConfigSpec{
+				Provider:    Name,
+				SenderEmail: "sender@example.com",
+				Graph:       &parhamv1.GraphConfig{AuthorityURL: authority.URL, BaseURL: api.URL},
+			},
+			SecretName: "graph-credentials",
+			Secret: map[string][]byte{
+				"tenantId":     []byte("tenant-id"),
+				"clientId":     []byte("client-id"),
+				"clientSecret": []byte(clientSecret),
+			},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(p.Validate()).To(Succeed())
+		return p
+	}
+
+	msg := &provider.Message{
+		From:    "sender@example.com",
+		To:      []mail.Address{{Address: "recipient@example.com"}},
+		Subject: "Test Subject",
+		Body:    "Test Body",
+	}
+
+	It("should send the message as the sender mailbox", func() {
+		res, err := newProvider("client-secret").Send(context.Background(), msg)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(res.MessageID).To(Equal("request-1"))
+
+		Expect(received.SaveToSentItems).To(BeTrue())
+		Expect(received.Message.Subject).To(Equal("Test Subject"))
+		Expect(received.Message.Body).To(Equal(itemBody{ContentType: "Text", Content: "Test Body"}))
+		Expect(received.Message.ToRecipients).To(ConsistOf(recipient{EmailAddress: emailAddress{Address: "recipient@example.com"}}))
+	})
+
+	It("should cache the token until it is about to expire", func() {
+		for i := 0; i < 3; i++ {
+			_, err := newProvider("client-secret").Send(context.Background(), msg)
+			Expect(err).NotTo(HaveOccurred())
+		}
+		Expect(tokenCalls).To(Equal(1))
+
+		now = now.Add(59 * time.Minute)
+		_, err := newProvider("client-secret").Send(context.Background(), msg)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(tokenCalls).To(Equal(2))
+	})
+
+	It("should refresh the token once when Graph rejects it", func() {
+		graphStatus = func(call int) int {
+			if call == 1 {
+				return http.StatusUnauthorized
+			}
+			return http.StatusAccepted
+		}
+
+		res, err := newProvider("client-secret").Send(context.Background(), msg)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(res.MessageID).To(Equal("request-2"))
+		Expect(tokenCalls).To(Equal(2))
+	})
+
+	It("should surface Graph errors", func() {
+		graphStatus = func(int) int { return http.StatusForbidden }
+
+		_, err := newProvider("client-secret").Send(context.Background(), msg)
+		Expect(err).To(MatchError("error response from Graph: 403 Forbidden: ErrorAccessDenied: Access is denied."))
+	})
+
+	It("should surface token endpoint errors", func() {
+		_, err := newProvider("wrong-secret").Send(context.Background(), msg)
+		Expect(err).To(MatchError(ContainSubstring("unable to get access token: 401 Unauthorized: invalid_client")))
+		Expect(graphCalls).To(BeZero())
+	})
+})
@@ -0,0 +1,80 @@
+package graph
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/parhamds/Email-Operator/internal/provider"
+)
+
+// tokens caches client-credentials tokens across sends.
+var tokens = provider.NewTokenCache()
+
+type tokenSource struct {
+	client       *http.Client
+	tokenURL     string
+	clientID     string
+	clientSecret string
+	scope        string
+}
+
+func (s *tokenSource) key() string {
+	sum := sha256.Sum256([]byte(strings.Join([]string{s.tokenURL, s.clientID, s.clientSecret, s.scope}, "\x00")))
+	return hex.EncodeToString(sum[:])
+}
+
+func (s *tokenSource) token(ctx context.Context) (string, error) {
+	return tokens.Get(ctx, s.key(), s.fetch)
+}
+
+type tokenResponse struct {
+	AccessToken      string `json:"access_token"`
+	ExpiresIn        int64  `json:"expires_in"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+func (s *tokenSource) fetch(ctx context.Context, now time.Time) (provider.Token, error) {
+	form := url.Values{
+		"grant_type":    {"client_credentials"},
+		"client_id":     {s.clientID},
+		"client_secret": {s.clientSecret},
+		"scope":         {s.scope},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return provider.Token{}, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	res, err := s.client.Do(req)
+	if err != nil {
+		return provider.Token{}, err
+	}
+	defer res.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(res.Body, 64<<10))
+	var out tokenResponse
+	_ = json.Unmarshal(body, &out)
+	if res.StatusCode != http.StatusOK {
+		if out.Error != "" {
+			return provider.Token{}, fmt.Errorf("unable to get access token: %s: %s: %s", res.Status, out.Error, out.ErrorDescription)
+		}
+		return provider.Token{}, fmt.Errorf("unable to get access token: %s", res.Status)
+	}
+	if out.AccessToken == "" {
+		return provider.Token{}, fmt.Errorf("unable to get access token: empty access_token in response")
+	}
+	return provider.Token{
+		AccessToken: out.AccessToken,
+		Expiry:      now.Add(time.Duration(out.ExpiresIn) * time.Second),
+	}, nil
+}
@@ -0,0 +1,93 @@
+package maildir
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/parhamds/Email-Operator/internal/message"
+	"github.com/parhamds/Email-Operator/internal/provider"
+)
+
+// Name is the EmailSenderConfig provider value of this provider.
+const Name = "Maildir"
+
+func init() {
+	provider.Register(Name, New)
+}
+
+type maildir struct {
+	directory string
+	builder   *message.Builder
+	now       func() time.Time
+}
+
+// New returns a provider delivering messages into a local Maildir.
+func New(cfg provider.Config) (provider.Provider, error) {
+	if cfg.Spec.Maildir == nil {
+		return nil, errors.New("maildir configuration is required for the Maildir provider")
+	}
+	return &maildir{directory: cfg.Spec.Maildir.Directory, builder: cfg.MessageBuilder(), now: time.Now}, nil
+}
+
+func (p *maildir) Validate() error {
+	if p.directory == "" {
+		return errors.New("maildir directory must not be empty")
+	}
+	return nil
+}
+
+func (p *maildir) Capabilities() provider.Capabilities {
+	return provider.Capabilities{Attachments: true, Headers: true}
+}
+
+// Send writes the message into tmp and moves it into new, as required by the
+// Maildir delivery protocol. The Maildir is created when it does not exist.
+func (p *maildir) Send(ctx context.Context, msg *provider.Message) (*provider.Result, error) {
+	for _, sub := range []string{"tmp", "new", "cur"} {
+		if err := os.MkdirAll(filepath.Join(p.directory, sub), 0o755); err != nil {
+			return nil, fmt.Errorf("unable to create maildir: %v", err)
+		}
+	}
+
+	now := p.now()
+	messageID := p.builder.MessageID(msg.From)
+	raw, err := msg.Raw(p.builder, messageID, now)
+	if err != nil {
+		return nil, err
+	}
+
+	name, err := uniqueName(now)
+	if err != nil {
+		return nil, err
+	}
+	tmp := filepath.Join(p.directory, "tmp", name)
+	if err := os.WriteFile(tmp, raw, 0o644); err != nil {
+		return nil, err
+	}
+	if err := os.Rename(tmp, filepath.Join(p.directory, "new", name)); err != nil {
+		_ = os.Remove(tmp)
+		return nil, err
+	}
+	return &provider.Result{MessageID: messageID}, nil
+}
+
+// uniqueName returns a Maildir file name of the form time.MusecPpidRrandom.host.
+func uniqueName(now time.Time) (string, error) {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	host, err := os.Hostname()
+	if err != nil {
+		host = "localhost"
+	}
+	host = strings.NewReplacer("/", `\057`, ":", `\072`).Replace(host)
+	return fmt.Sprintf("%d.M%dP%dR%s.%s", now.Unix(), now.Nanosecond()/1000, os.Getpid(), hex.EncodeToString(buf), host), nil
+}
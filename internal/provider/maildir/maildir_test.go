@@ -0,0 +1,50 @@
+package maildir_test
+
+import (
+	"context"
+	"net/mail"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	parhamv1 "github.com/parhamds/Email-Operator/api/v1"
+	"github.com/parhamds/Email-Operator/internal/provider"
+	"github.com/parhamds/Email-Operator/internal/provider/maildir"
+)
+
+var _ = Describe("Maildir provider", func() {
+	It("should deliver messages into new", func() {
+		dir := filepath.Join(GinkgoT().TempDir(), "Maildir")
+		p, err := maildir.New(provider.Config{
+			Spec: parhamv1.EmailSenderConfigSpec{
+				Provider:    maildir.Name,
+				SenderEmail: "sender@example.com",
+				Maildir:     &parhamv1.MaildirConfig{Directory: dir},
+			},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(p.Validate()).To(Succeed())
+		Expect(dir).NotTo(BeAnExistingFile())
+
+		res, err := p.Send(context.Background(), &provider.Message{
+			From:    "sender@example.com",
+			To:      []mail.Address{{Address: "recipient@example.com"}},
+			Subject: "Test Subject",
+			Body:    "Test Body",
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		entries, err := os.ReadDir(filepath.Join(dir, "new"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(entries).To(HaveLen(1))
+		data, err := os.ReadFile(filepath.Join(dir, "new", entries[0].Name()))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(data)).To(ContainSubstring("Message-ID: " + res.MessageID + "\r\n"))
+
+		Expect(filepath.Join(dir, "tmp")).To(BeADirectory())
+		Expect(os.ReadDir(filepath.Join(dir, "tmp"))).To(BeEmpty())
+		Expect(filepath.Join(dir, "cur")).To(BeADirectory())
+	})
+})
@@ -0,0 +1,195 @@
+package mailersend
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/mail"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/mailersend/mailersend-go"
+
+	"github.com/parhamds/Email-Operator/internal/provider"
+)
+
+// Name is the EmailSenderConfig provider value of this provider.
+const Name = "MailerSend"
+
+func init() {
+	provider.Register(Name, New)
+}
+
+type mailerSend struct {
+	cfg      provider.Config
+	apiToken string
+	baseURL  string
+	client   *http.Client
+	timeout  time.Duration
+}
+
+// New returns a provider sending through the MailerSend API.
+func New(cfg provider.Config) (provider.Provider, error) {
+	apiToken, err := cfg.SecretValue("apiToken")
+	if err != nil {
+		return nil, err
+	}
+	client, err := cfg.Transport.HTTPClient()
+	if err != nil {
+		return nil, err
+	}
+	p := &mailerSend{
+		cfg:      cfg,
+		apiToken: apiToken,
+		baseURL:  mailersend.APIBase,
+		client:   client,
+		timeout:  cfg.Timeout(),
+	}
+	if cfg.Spec.MailerSend != nil && cfg.Spec.MailerSend.BaseURL != "" {
+		base, err := url.Parse(strings.TrimSuffix(cfg.Spec.MailerSend.BaseURL, "/"))
+		if err != nil {
+			return nil, fmt.Errorf("invalid MailerSend base URL: %v", err)
+		}
+		p.baseURL = base.String()
+	}
+	return p, nil
+}
+
+func (p *mailerSend) Validate() error {
+	if p.apiToken == "" {
+		return fmt.Errorf("API token must not be empty")
+	}
+	return nil
+}
+
+func (p *mailerSend) Capabilities() provider.Capabilities {
+	return provider.Capabilities{Attachments: true, StoredTemplates: true, Headers: true, Tags: true, MaxTags: 5}
+}
+
+// request is the body of the email endpoint. It is sent without the client
+// library, which has no field for custom headers.
+type request struct {
+	*mailersend.Message
+	Headers []header `json:"headers,omitempty"`
+}
+
+type header struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+func (p *mailerSend) Send(ctx context.Context, msg *provider.Message) (*provider.Result, error) {
+	sendCtx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+
+	from := mailersend.From{
+		Name:  msg.From,
+		Email: msg.From,
+	}
+	if addr, err := provider.ParseAddress(msg.From); err == nil {
+		from.Email = addr.Address
+		from.Name = addr.Name
+		if from.Name == "" {
+			from.Name = addr.Address
+		}
+	}
+
+	message := &mailersend.Message{}
+	message.SetFrom(from)
+	message.SetRecipients(recipients(msg.To))
+	if len(msg.Cc) > 0 {
+		message.SetCc(recipients(msg.Cc))
+	}
+	if len(msg.Bcc) > 0 {
+		message.SetBcc(recipients(msg.Bcc))
+	}
+	if msg.ReplyTo != "" {
+		if addr, err := provider.ParseAddress(msg.ReplyTo); err == nil {
+			message.SetReplyTo(mailersend.Recipient{Name: addr.Name, Email: addr.Address})
+		}
+	}
+	if len(msg.Tags) > 0 {
+		message.SetTags(msg.Tags)
+	}
+	if msg.Subject != "" {
+		message.SetSubject(msg.Subject)
+	}
+	if msg.Template != nil {
+		message.SetTemplateID(msg.Template.ID)
+		message.SetPersonalization(personalization(msg.Recipients(), msg.Template.Variables))
+	} else {
+		message.SetText(msg.Body)
+	}
+	if msg.HTML != "" {
+		message.SetHTML(msg.HTML)
+	}
+	for _, a := range msg.Attachments {
+		attachment := mailersend.Attachment{
+			Content:     base64.StdEncoding.EncodeToString(a.Data),
+			Filename:    a.Filename,
+			Disposition: mailersend.DispositionAttachment,
+		}
+		if a.Inline() {
+			attachment.Disposition = mailersend.DispositionInline
+			attachment.ID = a.ContentID
+		}
+		message.AddAttachment(attachment)
+	}
+
+	body := request{Message: message}
+	for _, h := range msg.Headers {
+		body.Headers = append(body.Headers, header{Name: h.Name, Value: h.Value})
+	}
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(sendCtx, http.MethodPost, p.baseURL+"/email", bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Authorization", "Bearer "+p.apiToken)
+
+	res, err := p.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusAccepted {
+		return nil, fmt.Errorf("error response from MailerSend: %s", res.Status)
+	}
+
+	return &provider.Result{MessageID: res.Header.Get("X-Message-Id")}, nil
+}
+
+func recipients(addrs []mail.Address) []mailersend.Recipient {
+	out := make([]mailersend.Recipient, len(addrs))
+	for i, addr := range addrs {
+		out[i] = mailersend.Recipient{Name: addr.Name, Email: addr.Address}
+	}
+	return out
+}
+
+// personalization returns the template variables for every recipient,
+// MailerSend personalizes templates per recipient.
+func personalization(addrs []mail.Address, variables map[string]string) []mailersend.Personalization {
+	if len(variables) == 0 {
+		return nil
+	}
+	data := make(map[string]interface{}, len(variables))
+	for key, value := range variables {
+		data[key] = value
+	}
+	out := make([]mailersend.Personalization, len(addrs))
+	for i, addr := range addrs {
+		out[i] = mailersend.Personalization{Email: addr.Address, Data: data}
+	}
+	return out
+}
@@ -0,0 +1,14 @@
+package mailersend_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestMailerSend(t *testing.T) {
+	RegisterFailHandler(Fail)
+
+	RunSpecs(t, "MailerSend Provider Suite")
+}
@@ -0,0 +1,141 @@
+package mailersend_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/mail"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	parhamv1 "github.com/parhamds/Email-Operator/api/v1"
+	"github.com/parhamds/Email-Operator/internal/provider"
+	"github.com/parhamds/Email-Operator/internal/provider/mailersend"
+)
+
+var _ = Describe("MailerSend provider", func() {
+	var (
+		server  *httptest.Server
+		handler http.HandlerFunc
+	)
+
+	BeforeEach(func() {
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			handler(w, r)
+		}))
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	newProvider := func(timeout *metav1.Duration) provider.Provider {
+		p, err := mailersend.New(provider.Config{
+			Spec: parhamv1.EmailSenderConfigSpec{
+				Provider:    mailersend.Name,
+				SenderEmail: "sender@example.com",
+				Timeout:     timeout,
+				MailerSend:  &parhamv1.MailerSendConfig{BaseURL: server.URL + "/v1/"},
+			},
+			SecretName: "mailersend-token",
+			Secret:     map[string][]byte{"apiToken": []byte("mlsn.token")},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(p.Validate()).To(Succeed())
+		return p
+	}
+
+	msg := &provider.Message{
+		From:    "sender@example.com",
+		To:      []mail.Address{{Address: "recipient@example.com"}},
+		Subject: "Test Subject",
+		Body:    "Test Body",
+	}
+
+	It("should send the message through the configured base URL", func() {
+		handler = func(w http.ResponseWriter, r *http.Request) {
+			Expect(r.URL.Path).To(Equal("/v1/email"))
+			Expect(r.Header.Get("Authorization")).To(Equal("Bearer mlsn.token"))
+			var received map[string]any
+			Expect(json.NewDecoder(r.Body).Decode(&received)).To(Succeed())
+			Expect(received).To(HaveKeyWithValue("subject", "Test Subject"))
+			Expect(received).To(HaveKeyWithValue("text", "Test Body"))
+			w.Header().Set("X-Message-Id", "ms-message-id")
+			w.WriteHeader(http.StatusAccepted)
+		}
+
+		res, err := newProvider(nil).Send(context.Background(), msg)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(res.MessageID).To(Equal("ms-message-id"))
+	})
+
+	It("should send the sender name, reply address and tags", func() {
+		handler = func(w http.ResponseWriter, r *http.Request) {
+			var received map[string]any
+			Expect(json.NewDecoder(r.Body).Decode(&received)).To(Succeed())
+			Expect(received).To(HaveKeyWithValue("from", map[string]any{"name": "Billing", "email": "sender@example.com"}))
+			Expect(received).To(HaveKeyWithValue("reply_to", map[string]any{"name": "Support", "email": "support@example.com"}))
+			Expect(received).To(HaveKeyWithValue("tags", []any{"billing"}))
+			w.WriteHeader(http.StatusAccepted)
+		}
+
+		_, err := newProvider(nil).Send(context.Background(), &provider.Message{
+			From:    `"Billing" <sender@example.com>`,
+			To:      []mail.Address{{Address: "recipient@example.com"}},
+			Subject: "Test Subject",
+			Body:    "Test Body",
+			ReplyTo: "Support <support@example.com>",
+			Tags:    []string{"billing"},
+		})
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should send custom headers", func() {
+		handler = func(w http.ResponseWriter, r *http.Request) {
+			var received map[string]any
+			Expect(json.NewDecoder(r.Body).Decode(&received)).To(Succeed())
+			Expect(received).To(HaveKeyWithValue("headers", []any{
+				map[string]any{"name": "X-Campaign", "value": "spring"},
+			}))
+			Expect(received).To(HaveKeyWithValue("subject", "Test Subject"))
+			w.WriteHeader(http.StatusAccepted)
+		}
+
+		_, err := newProvider(nil).Send(context.Background(), &provider.Message{
+			From:    "sender@example.com",
+			To:      []mail.Address{{Address: "recipient@example.com"}},
+			Subject: "Test Subject",
+			Body:    "Test Body",
+			Headers: []provider.Header{{Name: "X-Campaign", Value: "spring"}},
+		})
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should report that metadata is not supported", func() {
+		caps := newProvider(nil).Capabilities()
+		Expect(caps.Tags).To(BeTrue())
+		Expect(caps.Metadata).To(BeFalse())
+	})
+
+	It("should report error responses", func() {
+		handler = func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+
+		_, err := newProvider(nil).Send(context.Background(), msg)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should give up after the configured timeout", func() {
+		handler = func(w http.ResponseWriter, r *http.Request) {
+			time.Sleep(200 * time.Millisecond)
+		}
+
+		_, err := newProvider(&metav1.Duration{Duration: 20 * time.Millisecond}).Send(context.Background(), msg)
+		Expect(err).To(MatchError(ContainSubstring("context deadline exceeded")))
+	})
+})
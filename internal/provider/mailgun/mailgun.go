@@ -0,0 +1,176 @@
+package mailgun
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/mail"
+	"strings"
+	"time"
+
+	mailgun "github.com/mailgun/mailgun-go/v4"
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	"github.com/parhamds/Email-Operator/internal/message"
+	"github.com/parhamds/Email-Operator/internal/provider"
+)
+
+// Name is the EmailSenderConfig provider value of this provider.
+const Name = "Mailgun"
+
+const (
+	// RegionUS is the default Mailgun region.
+	RegionUS = "US"
+	// RegionEU is the Mailgun region of accounts hosted in the EU.
+	RegionEU = "EU"
+)
+
+func init() {
+	provider.Register(Name, New)
+}
+
+type mailgunProvider struct {
+	cfg      provider.Config
+	apiToken string
+	apiBase  string
+	client   *http.Client
+	timeout  time.Duration
+}
+
+// New returns a provider sending through the Mailgun API.
+func New(cfg provider.Config) (provider.Provider, error) {
+	apiToken, err := cfg.SecretValue("apiToken")
+	if err != nil {
+		return nil, err
+	}
+	client, err := cfg.Transport.HTTPClient()
+	if err != nil {
+		return nil, err
+	}
+	p := &mailgunProvider{
+		cfg:      cfg,
+		apiToken: apiToken,
+		apiBase:  mailgun.APIBaseUS,
+		client:   client,
+		timeout:  cfg.Timeout(),
+	}
+	if spec := cfg.Spec.Mailgun; spec != nil {
+		switch {
+		case spec.BaseURL != "":
+			p.apiBase = strings.TrimSuffix(spec.BaseURL, "/")
+		case strings.EqualFold(spec.Region, RegionEU):
+			p.apiBase = mailgun.APIBaseEU
+		}
+	}
+	return p, nil
+}
+
+func (p *mailgunProvider) Validate() error {
+	if p.apiToken == "" {
+		return fmt.Errorf("API token must not be empty")
+	}
+	_, err := p.domain()
+	return err
+}
+
+func (p *mailgunProvider) Capabilities() provider.Capabilities {
+	return provider.Capabilities{Attachments: true, StoredTemplates: true, Headers: true, Tags: true, MaxTags: 3, Metadata: true}
+}
+
+func (p *mailgunProvider) Send(ctx context.Context, msg *provider.Message) (*provider.Result, error) {
+	domain, err := p.domain()
+	if err != nil {
+		return nil, err
+	}
+
+	mg := mailgun.NewMailgun(domain, p.apiToken)
+	mg.SetAPIBase(p.apiBase)
+	mg.SetClient(p.client)
+	sendCtx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+
+	m := mg.NewMessage(
+		msg.From,
+		msg.Subject,
+		msg.Body,
+		formatted(msg.To)...,
+	)
+	for _, cc := range formatted(msg.Cc) {
+		m.AddCC(cc)
+	}
+	for _, bcc := range formatted(msg.Bcc) {
+		m.AddBCC(bcc)
+	}
+	if msg.HTML != "" {
+		m.SetHtml(msg.HTML)
+	}
+	if msg.Template != nil {
+		m.SetTemplate(msg.Template.ID)
+		for key, value := range msg.Template.Variables {
+			if err := m.AddTemplateVariable(key, value); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if msg.ReplyTo != "" {
+		m.SetReplyTo(msg.ReplyTo)
+	}
+	for _, h := range msg.Headers {
+		m.AddHeader(h.Name, h.Value)
+	}
+	if len(msg.Tags) > 0 {
+		if err := m.AddTag(msg.Tags...); err != nil {
+			return nil, err
+		}
+	}
+	for key, value := range msg.Metadata {
+		if err := m.AddVariable(key, value); err != nil {
+			return nil, err
+		}
+	}
+	for _, a := range msg.Attachments {
+		if a.Inline() {
+			// Mailgun sets the Content-ID of inline parts to their filename.
+			m.AddReaderInline(a.ContentID, io.NopCloser(bytes.NewReader(a.Data)))
+			continue
+		}
+		m.AddBufferAttachment(a.Filename, a.Data)
+	}
+
+	_, id, err := mg.Send(sendCtx, m)
+	if err != nil {
+		return nil, err
+	}
+
+	return &provider.Result{MessageID: id}, nil
+}
+
+// formatted returns every address in the form Mailgun takes, with its
+// display name.
+func formatted(addrs []mail.Address) []string {
+	out := make([]string, len(addrs))
+	for i := range addrs {
+		out[i] = message.FormatAddressList(addrs[i : i+1])
+	}
+	return out
+}
+
+// domain returns the Mailgun sending domain, either configured explicitly or
+// taken from the sender address.
+func (p *mailgunProvider) domain() (string, error) {
+	if p.cfg.Spec.Mailgun != nil && p.cfg.Spec.Mailgun.Domain != "" {
+		domain := p.cfg.Spec.Mailgun.Domain
+		// Domain names are case insensitive, DNS-1123 subdomains lowercase.
+		if errs := validation.IsDNS1123Subdomain(strings.ToLower(domain)); len(errs) > 0 {
+			return "", fmt.Errorf("invalid sending domain %q: %s", domain, strings.Join(errs, ", "))
+		}
+		return strings.ToLower(domain), nil
+	}
+	addr, err := provider.ParseAddress(p.cfg.Spec.SenderEmail)
+	if err != nil {
+		return "", fmt.Errorf("invalid sender email format: %v", err)
+	}
+	return addr.Address[strings.LastIndex(addr.Address, "@")+1:], nil
+}
@@ -0,0 +1,14 @@
+package mailgun_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestMailgun(t *testing.T) {
+	RegisterFailHandler(Fail)
+
+	RunSpecs(t, "Mailgun Provider Suite")
+}
@@ -0,0 +1,177 @@
+package mailgun_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/mail"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	parhamv1 "github.com/parhamds/Email-Operator/api/v1"
+	"github.com/parhamds/Email-Operator/internal/provider"
+	"github.com/parhamds/Email-Operator/internal/provider/mailgun"
+)
+
+var _ = Describe("Mailgun provider", func() {
+	var (
+		server  *httptest.Server
+		handler http.HandlerFunc
+	)
+
+	BeforeEach(func() {
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			handler(w, r)
+		}))
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	newConfig := func(senderEmail, domain string, timeout *metav1.Duration) provider.Config {
+		return provider.Config{
+			Spec: parhamv1.EmailSenderConfigSpec{
+				Provider:    mailgun.Name,
+				SenderEmail: senderEmail,
+				Timeout:     timeout,
+				Mailgun: &parhamv1.MailgunConfig{
+					Region:  mailgun.RegionEU,
+					BaseURL: server.URL + "/v3/",
+					Domain:  domain,
+				},
+			},
+			SecretName: "mailgun-token",
+			Secret:     map[string][]byte{"apiToken": []byte("key-token")},
+		}
+	}
+
+	newProvider := func(timeout *metav1.Duration) provider.Provider {
+		p, err := mailgun.New(newConfig("sender@mg.example.com", "", timeout))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(p.Validate()).To(Succeed())
+		return p
+	}
+
+	msg := &provider.Message{
+		From:    "sender@mg.example.com",
+		To:      []mail.Address{{Address: "recipient@example.com"}},
+		Subject: "Test Subject",
+		Body:    "Test Body",
+	}
+
+	It("should send the message through the configured base URL", func() {
+		handler = func(w http.ResponseWriter, r *http.Request) {
+			Expect(r.URL.Path).To(Equal("/v3/mg.example.com/messages"))
+			user, password, ok := r.BasicAuth()
+			Expect(ok).To(BeTrue())
+			Expect(user).To(Equal("api"))
+			Expect(password).To(Equal("key-token"))
+			Expect(r.ParseMultipartForm(1 << 20)).To(Succeed())
+			Expect(r.FormValue("to")).To(Equal("recipient@example.com"))
+			Expect(r.FormValue("subject")).To(Equal("Test Subject"))
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"id":"<mg-message-id@mg.example.com>","message":"Queued. Thank you."}`))
+		}
+
+		res, err := newProvider(nil).Send(context.Background(), msg)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(res.MessageID).To(Equal("<mg-message-id@mg.example.com>"))
+	})
+
+	It("should send the reply address, headers, tags and variables", func() {
+		handler = func(w http.ResponseWriter, r *http.Request) {
+			Expect(r.ParseMultipartForm(1 << 20)).To(Succeed())
+			Expect(r.FormValue("h:Reply-To")).To(Equal("Support <support@example.com>"))
+			Expect(r.FormValue("h:X-Campaign")).To(Equal("spring"))
+			Expect(r.MultipartForm.Value["o:tag"]).To(Equal([]string{"billing", "invoice"}))
+			Expect(r.FormValue("v:customer")).To(Equal("42"))
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"id":"<mg-message-id@mg.example.com>","message":"Queued. Thank you."}`))
+		}
+
+		_, err := newProvider(nil).Send(context.Background(), &provider.Message{
+			From:     "sender@mg.example.com",
+			To:       []mail.Address{{Address: "recipient@example.com"}},
+			Subject:  "Test Subject",
+			Body:     "Test Body",
+			ReplyTo:  "Support <support@example.com>",
+			Headers:  []provider.Header{{Name: "X-Campaign", Value: "spring"}},
+			Tags:     []string{"billing", "invoice"},
+			Metadata: map[string]string{"customer": "42"},
+		})
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should send a stored template with its variables", func() {
+		handler = func(w http.ResponseWriter, r *http.Request) {
+			Expect(r.ParseMultipartForm(1 << 20)).To(Succeed())
+			Expect(r.FormValue("template")).To(Equal("welcome"))
+			Expect(r.FormValue("h:X-Mailgun-Variables")).To(MatchJSON(`{"name":"Jane"}`))
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"id":"<mg-message-id@mg.example.com>","message":"Queued. Thank you."}`))
+		}
+
+		_, err := newProvider(nil).Send(context.Background(), &provider.Message{
+			From:     "sender@mg.example.com",
+			To:       []mail.Address{{Address: "recipient@example.com"}},
+			Subject:  "Welcome",
+			Template: &provider.StoredTemplate{ID: "welcome", Variables: map[string]string{"name": "Jane"}},
+		})
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should give up after the configured timeout", func() {
+		handler = func(w http.ResponseWriter, r *http.Request) {
+			time.Sleep(200 * time.Millisecond)
+		}
+
+		_, err := newProvider(&metav1.Duration{Duration: 20 * time.Millisecond}).Send(context.Background(), msg)
+		Expect(err).To(MatchError(ContainSubstring("context deadline exceeded")))
+	})
+
+	It("should send through the configured sending domain as a display name sender", func() {
+		handler = func(w http.ResponseWriter, r *http.Request) {
+			Expect(r.URL.Path).To(Equal("/v3/mg.example.com/messages"))
+			Expect(r.ParseMultipartForm(1 << 20)).To(Succeed())
+			Expect(r.FormValue("from")).To(Equal(`"Billing" <billing@example.com>`))
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"id":"<mg-message-id@mg.example.com>","message":"Queued. Thank you."}`))
+		}
+
+		p, err := mailgun.New(newConfig(`"Billing" <billing@example.com>`, "MG.Example.com", nil))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(p.Validate()).To(Succeed())
+
+		_, err = p.Send(context.Background(), &provider.Message{
+			From:    `"Billing" <billing@example.com>`,
+			To:      []mail.Address{{Address: "recipient@example.com"}},
+			Subject: "Test Subject",
+			Body:    "Test Body",
+		})
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should derive the sending domain from a display name sender", func() {
+		handler = func(w http.ResponseWriter, r *http.Request) {
+			Expect(r.URL.Path).To(Equal("/v3/example.com/messages"))
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"id":"<id@example.com>","message":"Queued. Thank you."}`))
+		}
+
+		p, err := mailgun.New(newConfig("Billing <billing@example.com>", "", nil))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(p.Validate()).To(Succeed())
+		_, err = p.Send(context.Background(), msg)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should reject an invalid sending domain", func() {
+		p, err := mailgun.New(newConfig("billing@example.com", "MG_example", nil))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(p.Validate()).To(MatchError(ContainSubstring(`invalid sending domain "MG_example"`)))
+	})
+})
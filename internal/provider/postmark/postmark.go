@@ -0,0 +1,206 @@
+package postmark
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/parhamds/Email-Operator/internal/message"
+	"github.com/parhamds/Email-Operator/internal/provider"
+)
+
+// Name is the EmailSenderConfig provider value of this provider.
+const Name = "Postmark"
+
+// DefaultBaseURL is the Postmark API base URL used when none is configured.
+const DefaultBaseURL = "https://api.postmarkapp.com"
+
+// DefaultMessageStream is the message stream used when none is configured.
+const DefaultMessageStream = "outbound"
+
+func init() {
+	provider.Register(Name, New)
+}
+
+type postmark struct {
+	baseURL       string
+	messageStream string
+	serverToken   string
+	client        *http.Client
+	timeout       time.Duration
+}
+
+// New returns a provider sending through the Postmark email API.
+func New(cfg provider.Config) (provider.Provider, error) {
+	client, err := cfg.Transport.HTTPClient()
+	if err != nil {
+		return nil, err
+	}
+	serverToken, err := cfg.SecretValue("apiToken")
+	if err != nil {
+		return nil, err
+	}
+
+	p := &postmark{
+		baseURL:       DefaultBaseURL,
+		messageStream: DefaultMessageStream,
+		serverToken:   serverToken,
+		client:        client,
+		timeout:       cfg.Timeout(),
+	}
+	if cfg.Spec.Postmark != nil {
+		if cfg.Spec.Postmark.BaseURL != "" {
+			p.baseURL = strings.TrimSuffix(cfg.Spec.Postmark.BaseURL, "/")
+		}
+		if cfg.Spec.Postmark.MessageStream != "" {
+			p.messageStream = cfg.Spec.Postmark.MessageStream
+		}
+	}
+	return p, nil
+}
+
+func (p *postmark) Validate() error {
+	if p.serverToken == "" {
+		return fmt.Errorf("server token must not be empty")
+	}
+	return nil
+}
+
+func (p *postmark) Capabilities() provider.Capabilities {
+	return provider.Capabilities{Attachments: true, StoredTemplates: true, Headers: true, Tags: true, MaxTags: 1, Metadata: true}
+}
+
+type emailRequest struct {
+	From          string            `json:"From"`
+	To            string            `json:"To"`
+	Cc            string            `json:"Cc,omitempty"`
+	Bcc           string            `json:"Bcc,omitempty"`
+	Subject       string            `json:"Subject,omitempty"`
+	TextBody      string            `json:"TextBody,omitempty"`
+	HtmlBody      string            `json:"HtmlBody,omitempty"`
+	MessageStream string            `json:"MessageStream"`
+	Attachments   []attachment      `json:"Attachments,omitempty"`
+	ReplyTo       string            `json:"ReplyTo,omitempty"`
+	Headers       []header          `json:"Headers,omitempty"`
+	Tag           string            `json:"Tag,omitempty"`
+	Metadata      map[string]string `json:"Metadata,omitempty"`
+	TemplateId    int64             `json:"TemplateId,omitempty"`
+	TemplateAlias string            `json:"TemplateAlias,omitempty"`
+	// TemplateModel is required with a template, even when it is empty.
+	TemplateModel any `json:"TemplateModel,omitempty"`
+}
+
+type header struct {
+	Name  string `json:"Name"`
+	Value string `json:"Value"`
+}
+
+type attachment struct {
+	Name        string `json:"Name"`
+	Content     string `json:"Content"`
+	ContentType string `json:"ContentType"`
+	ContentID   string `json:"ContentID,omitempty"`
+}
+
+func attachments(in []provider.Attachment) []attachment {
+	var out []attachment
+	for _, a := range in {
+		att := attachment{
+			Name:        a.Filename,
+			Content:     base64.StdEncoding.EncodeToString(a.Data),
+			ContentType: a.ContentType,
+		}
+		if a.Inline() {
+			att.ContentID = "cid:" + a.ContentID
+		}
+		out = append(out, att)
+	}
+	return out
+}
+
+type emailResponse struct {
+	MessageID string `json:"MessageID"`
+	ErrorCode int    `json:"ErrorCode"`
+	Message   string `json:"Message"`
+}
+
+func (p *postmark) Send(ctx context.Context, msg *provider.Message) (*provider.Result, error) {
+	sendCtx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+
+	request := emailRequest{
+		From:          msg.From,
+		To:            message.FormatAddressList(msg.To),
+		Cc:            message.FormatAddressList(msg.Cc),
+		Bcc:           message.FormatAddressList(msg.Bcc),
+		Subject:       msg.Subject,
+		TextBody:      msg.Body,
+		HtmlBody:      msg.HTML,
+		MessageStream: p.messageStream,
+		Attachments:   attachments(msg.Attachments),
+		ReplyTo:       msg.ReplyTo,
+		Metadata:      msg.Metadata,
+	}
+	for _, h := range msg.Headers {
+		request.Headers = append(request.Headers, header{Name: h.Name, Value: h.Value})
+	}
+	if len(msg.Tags) > 0 {
+		// Postmark takes a single tag per message, as its MaxTags reports.
+		request.Tag = msg.Tags[0]
+	}
+	endpoint := "/email"
+	if msg.Template != nil {
+		// Templates are referenced by their numeric ID or by their alias.
+		endpoint = "/email/withTemplate"
+		if id, err := strconv.ParseInt(msg.Template.ID, 10, 64); err == nil {
+			request.TemplateId = id
+		} else {
+			request.TemplateAlias = msg.Template.ID
+		}
+		model := msg.Template.Variables
+		if model == nil {
+			model = map[string]string{}
+		}
+		request.TemplateModel = model
+		request.Subject, request.TextBody, request.HtmlBody = "", "", ""
+	}
+	payload, err := json.Marshal(request)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(sendCtx, http.MethodPost, p.baseURL+endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Postmark-Server-Token", p.serverToken)
+
+	res, err := p.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(res.Body, 64<<10))
+	var out emailResponse
+	if err := json.Unmarshal(body, &out); err != nil {
+		if res.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("error response from Postmark: %s", res.Status)
+		}
+		return nil, fmt.Errorf("invalid response from Postmark: %v", err)
+	}
+	if res.StatusCode != http.StatusOK || out.ErrorCode != 0 {
+		return nil, fmt.Errorf("error response from Postmark: %s: ErrorCode %d: %s", res.Status, out.ErrorCode, out.Message)
+	}
+
+	return &provider.Result{MessageID: out.MessageID}, nil
+}
@@ -0,0 +1,14 @@
+package postmark_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestPostmark(t *testing.T) {
+	RegisterFailHandler(Fail)
+
+	RunSpecs(t, "Postmark Provider Suite")
+}
@@ -0,0 +1,100 @@
+package postmark_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/mail"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	parhamv1 "github.com/parhamds/Email-Operator/api/v1"
+	"github.com/parhamds/Email-Operator/internal/provider"
+	"github.com/parhamds/Email-Operator/internal/provider/postmark"
+)
+
+var _ = Describe("Postmark provider", func() {
+	var (
+		server   *httptest.Server
+		handler  http.HandlerFunc
+		received map[string]string
+		token    string
+	)
+
+	BeforeEach(func() {
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			Expect(r.URL.Path).To(Equal("/email"))
+			token = r.Header.Get("X-Postmark-Server-Token")
+			Expect(json.NewDecoder(r.Body).Decode(&received)).To(Succeed())
+			handler(w, r)
+		}))
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	newProvider := func(stream string) provider.Provider {
+		p, err := postmark.New(provider.Config{
+			Spec: parhamv1.EmailSenderConfigSpec{
+				Provider:    postmark.Name,
+				SenderEmail: "sender@example.com",
+				Postmark:    &parhamv1.PostmarkConfig{BaseURL: server.URL, MessageStream: stream},
+			},
+			SecretName: "postmark-token",
+			Secret:     map[string][]byte{"apiToken": []byte("server-token")},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(p.Validate()).To(Succeed())
+		return p
+	}
+
+	msg := &provider.Message{
+		From:    "sender@example.com",
+		To:      []mail.Address{{Address: "recipient@example.com"}},
+		Subject: "Test Subject",
+		Body:    "Test Body",
+	}
+
+	It("should send through the configured message stream", func() {
+		handler = func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`{"To":"recipient@example.com","MessageID":"b7bc2f4a-e38e-4336-af7d-e6c392c2f817","ErrorCode":0,"Message":"OK"}`))
+		}
+
+		res, err := newProvider("broadcast").Send(context.Background(), msg)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(res.MessageID).To(Equal("b7bc2f4a-e38e-4336-af7d-e6c392c2f817"))
+
+		Expect(token).To(Equal("server-token"))
+		Expect(received).To(Equal(map[string]string{
+			"From":          "sender@example.com",
+			"To":            "recipient@example.com",
+			"Subject":       "Test Subject",
+			"TextBody":      "Test Body",
+			"MessageStream": "broadcast",
+		}))
+	})
+
+	It("should default to the outbound message stream", func() {
+		handler = func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`{"MessageID":"id","ErrorCode":0,"Message":"OK"}`))
+		}
+
+		_, err := newProvider("").Send(context.Background(), msg)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(received).To(HaveKeyWithValue("MessageStream", "outbound"))
+	})
+
+	It("should surface the Postmark ErrorCode and Message", func() {
+		handler = func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			_, _ = w.Write([]byte(`{"ErrorCode":406,"Message":"You tried to send to a recipient that has been marked as inactive."}`))
+		}
+
+		_, err := newProvider("outbound").Send(context.Background(), msg)
+		Expect(err).To(MatchError("error response from Postmark: 422 Unprocessable Entity: ErrorCode 406: " +
+			"You tried to send to a recipient that has been marked as inactive."))
+	})
+})
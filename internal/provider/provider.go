@@ -0,0 +1,255 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/mail"
+	"net/textproto"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	parhamv1 "github.com/parhamds/Email-Operator/api/v1"
+	"github.com/parhamds/Email-Operator/internal/message"
+)
+
+// Config carries everything a provider needs to reach its backend: the
+// EmailSenderConfig spec and the data of the Secret it references.
+type Config struct {
+	Spec       parhamv1.EmailSenderConfigSpec
+	SecretName string
+	Secret     map[string][]byte
+	// Transport configures the connections to the backend.
+	Transport TransportOptions
+}
+
+// DefaultTimeout bounds provider requests when the EmailSenderConfig does not
+// set a timeout.
+const DefaultTimeout = 10 * time.Second
+
+// Timeout returns the duration a single provider request may take.
+func (c Config) Timeout() time.Duration {
+	if c.Spec.Timeout != nil && c.Spec.Timeout.Duration > 0 {
+		return c.Spec.Timeout.Duration
+	}
+	return DefaultTimeout
+}
+
+// SecretValue returns the value stored under key in the referenced Secret.
+func (c Config) SecretValue(key string) (string, error) {
+	value, ok := c.Secret[key]
+	if !ok {
+		return "", fmt.Errorf("secret %s does not contain key %s", c.SecretName, key)
+	}
+	return string(value), nil
+}
+
+// MessageBuilder returns the builder of full MIME messages, generating
+// Message-IDs on the configured domain.
+func (c Config) MessageBuilder() *message.Builder {
+	return &message.Builder{Domain: c.Spec.MessageIDDomain}
+}
+
+// Message is a single email handed to a provider.
+type Message struct {
+	From    string
+	To      []mail.Address
+	Cc      []mail.Address
+	Bcc     []mail.Address
+	Subject string
+	// Body is the plain text body.
+	Body string
+	// HTML is the optional HTML alternative of Body.
+	HTML        string
+	Attachments []Attachment
+	// ReplyTo is the optional reply address, formatted like From.
+	ReplyTo string
+	// Headers are custom headers, none of them is protected.
+	Headers []Header
+	// Tags and Metadata are passed to providers with native equivalents.
+	Tags     []string
+	Metadata map[string]string
+	// Template replaces the bodies with a template stored by the provider,
+	// for providers with the StoredTemplates capability.
+	Template *StoredTemplate
+}
+
+// StoredTemplate is a template stored by the provider.
+type StoredTemplate struct {
+	ID        string
+	Variables map[string]string
+}
+
+// Header is a custom message header.
+type Header struct {
+	Name  string
+	Value string
+}
+
+// protectedHeaders are set by the operator or the providers and cannot be
+// set as custom headers.
+var protectedHeaders = map[string]bool{
+	"Bcc":                       true,
+	"Cc":                        true,
+	"Content-Disposition":       true,
+	"Content-Id":                true,
+	"Content-Transfer-Encoding": true,
+	"Content-Type":              true,
+	"Date":                      true,
+	"Dkim-Signature":            true,
+	"From":                      true,
+	"Message-Id":                true,
+	"Mime-Version":              true,
+	"Received":                  true,
+	"Reply-To":                  true,
+	"Return-Path":               true,
+	"Sender":                    true,
+	"Subject":                   true,
+	"To":                        true,
+}
+
+// IsProtectedHeader reports whether the header cannot be set as a custom
+// header.
+func IsProtectedHeader(name string) bool {
+	return protectedHeaders[textproto.CanonicalMIMEHeaderKey(name)]
+}
+
+// Attachment is a file attached to a message.
+type Attachment struct {
+	Filename    string
+	ContentType string
+	// ContentID is set for attachments embedded inline in the HTML body.
+	ContentID string
+	Data      []byte
+}
+
+// Inline reports whether the attachment is embedded in the HTML body.
+func (a Attachment) Inline() bool {
+	return a.ContentID != ""
+}
+
+// Result describes a message accepted by a provider.
+type Result struct {
+	MessageID string
+	// ThreadID is set by providers that group messages into threads.
+	ThreadID string
+	// Rejected lists the recipients the provider refused while accepting
+	// the message for the others.
+	Rejected []RejectedRecipient
+}
+
+// RejectedRecipient is a recipient refused by the provider.
+type RejectedRecipient struct {
+	Address string
+	Reason  string
+}
+
+// PermanentError is an error that sending through another provider would not
+// fix, such as a message that cannot be built. The fallbacks of a sender
+// config are not tried after a PermanentError.
+type PermanentError struct {
+	Err error
+}
+
+func (e *PermanentError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *PermanentError) Unwrap() error {
+	return e.Err
+}
+
+// Permanent wraps err in a PermanentError.
+func Permanent(err error) error {
+	return &PermanentError{Err: err}
+}
+
+// IsPermanent reports whether err wraps a PermanentError.
+func IsPermanent(err error) bool {
+	var permanent *PermanentError
+	return errors.As(err, &permanent)
+}
+
+// Recipients returns the To, Cc and Bcc recipients of the message.
+func (m *Message) Recipients() []mail.Address {
+	recipients := make([]mail.Address, 0, len(m.To)+len(m.Cc)+len(m.Bcc))
+	recipients = append(recipients, m.To...)
+	recipients = append(recipients, m.Cc...)
+	return append(recipients, m.Bcc...)
+}
+
+// Capabilities reports the optional features a provider supports.
+type Capabilities struct {
+	Attachments     bool
+	StoredTemplates bool
+	// Headers is set by providers able to add custom headers.
+	Headers bool
+	// Tags is set by providers able to tag messages. MaxTags bounds the
+	// number of tags of a message when it is not 0.
+	Tags    bool
+	MaxTags int
+	// Metadata is set by providers able to carry custom metadata.
+	Metadata bool
+}
+
+// Provider is a backend able to deliver email messages.
+type Provider interface {
+	// Validate checks the provider configuration without sending anything.
+	Validate() error
+	// Capabilities reports the optional features of the provider.
+	Capabilities() Capabilities
+	// Send delivers msg and returns the provider assigned message id.
+	Send(ctx context.Context, msg *Message) (*Result, error)
+}
+
+// Factory builds a Provider from a sender configuration.
+type Factory func(cfg Config) (Provider, error)
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[string]registration{}
+)
+
+type registration struct {
+	name    string
+	factory Factory
+}
+
+// Register makes a provider available under name. Names are matched case
+// insensitively. Register panics if name is already taken.
+func Register(name string, factory Factory) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	key := strings.ToLower(name)
+	if _, ok := registry[key]; ok {
+		panic(fmt.Sprintf("provider %s already registered", name))
+	}
+	registry[key] = registration{name: name, factory: factory}
+}
+
+// New builds the provider registered under cfg.Spec.Provider.
+func New(cfg Config) (Provider, error) {
+	registryMu.RLock()
+	reg, ok := registry[strings.ToLower(cfg.Spec.Provider)]
+	registryMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown provider %q, must be one of: %s", cfg.Spec.Provider, strings.Join(Names(), ", "))
+	}
+	return reg.factory(cfg)
+}
+
+// Names returns the names of all registered providers.
+func Names() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	names := make([]string, 0, len(registry))
+	for _, reg := range registry {
+		names = append(names, reg.name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,90 @@
+package provider_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/mail"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	parhamv1 "github.com/parhamds/Email-Operator/api/v1"
+	"github.com/parhamds/Email-Operator/internal/provider"
+)
+
+type fakeProvider struct {
+	cfg provider.Config
+}
+
+func (p *fakeProvider) Validate() error { return nil }
+
+func (p *fakeProvider) Capabilities() provider.Capabilities { return provider.Capabilities{} }
+
+func (p *fakeProvider) Send(ctx context.Context, msg *provider.Message) (*provider.Result, error) {
+	return &provider.Result{MessageID: "fake-" + msg.To[0].Address}, nil
+}
+
+func init() {
+	provider.Register("FakeProvider", func(cfg provider.Config) (provider.Provider, error) {
+		return &fakeProvider{cfg: cfg}, nil
+	})
+}
+
+var _ = Describe("Provider registry", func() {
+	It("should build a registered provider case insensitively", func() {
+		p, err := provider.New(provider.Config{Spec: parhamv1.EmailSenderConfigSpec{Provider: "fakeprovider"}})
+		Expect(err).NotTo(HaveOccurred())
+
+		res, err := p.Send(context.Background(), &provider.Message{To: []mail.Address{{Address: "someone@example.com"}}})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(res.MessageID).To(Equal("fake-someone@example.com"))
+	})
+
+	It("should report an unknown provider", func() {
+		_, err := provider.New(provider.Config{Spec: parhamv1.EmailSenderConfigSpec{Provider: "MailGunn"}})
+		Expect(err).To(MatchError(ContainSubstring(`unknown provider "MailGunn"`)))
+		Expect(err).To(MatchError(ContainSubstring("FakeProvider")))
+	})
+
+	It("should refuse to register a name twice", func() {
+		Expect(func() {
+			provider.Register("FAKEPROVIDER", nil)
+		}).To(Panic())
+	})
+
+	It("should report missing secret keys", func() {
+		cfg := provider.Config{SecretName: "token", Secret: map[string][]byte{}}
+		_, err := cfg.SecretValue("apiToken")
+		Expect(err).To(MatchError("secret token does not contain key apiToken"))
+		Expect(provider.IsPermanent(err)).To(BeFalse())
+	})
+
+	It("should find permanent errors in wrapped errors", func() {
+		err := fmt.Errorf("failed to send email: %w", provider.Permanent(errors.New("invalid body")))
+		Expect(err).To(MatchError("failed to send email: invalid body"))
+		Expect(provider.IsPermanent(err)).To(BeTrue())
+		Expect(provider.IsPermanent(errors.New("503 Service Unavailable"))).To(BeFalse())
+	})
+
+	It("should default the request timeout", func() {
+		Expect(provider.Config{}.Timeout()).To(Equal(provider.DefaultTimeout))
+
+		cfg := provider.Config{Spec: parhamv1.EmailSenderConfigSpec{Timeout: &metav1.Duration{Duration: time.Minute}}}
+		Expect(cfg.Timeout()).To(Equal(time.Minute))
+	})
+
+	It("should parse display name addresses", func() {
+		Expect(provider.BareAddress(`"Billing" <billing@example.com>`)).To(Equal("billing@example.com"))
+		Expect(provider.BareAddress("billing@example.com")).To(Equal("billing@example.com"))
+	})
+
+	It("should generate Message-IDs on the configured domain", func() {
+		Expect(provider.Config{}.MessageBuilder().MessageID("billing@example.com")).To(HaveSuffix("@example.com>"))
+
+		cfg := provider.Config{Spec: parhamv1.EmailSenderConfigSpec{MessageIDDomain: "mail.example.com"}}
+		Expect(cfg.MessageBuilder().MessageID("billing@example.com")).To(HaveSuffix("@mail.example.com>"))
+	})
+})
@@ -0,0 +1,52 @@
+package provider
+
+import (
+	"net/mail"
+	"time"
+
+	"github.com/parhamds/Email-Operator/internal/message"
+)
+
+// Raw renders the message with b as an RFC 5322 message for transports that
+// take the full message rather than separate fields. Bcc recipients are left
+// out. A message that cannot be built is a PermanentError.
+func (m *Message) Raw(b *message.Builder, messageID string, date time.Time) ([]byte, error) {
+	mm := &message.Message{
+		From:      parseAddress(m.From),
+		To:        m.To,
+		Cc:        m.Cc,
+		Subject:   m.Subject,
+		MessageID: messageID,
+		Date:      date,
+		Text:      m.Body,
+		HTML:      m.HTML,
+	}
+	if m.ReplyTo != "" {
+		mm.ReplyTo = []mail.Address{parseAddress(m.ReplyTo)}
+	}
+	for _, h := range m.Headers {
+		mm.Headers = append(mm.Headers, message.Header{Name: h.Name, Value: h.Value})
+	}
+	for _, a := range m.Attachments {
+		mm.Attachments = append(mm.Attachments, message.Attachment{
+			Filename:    a.Filename,
+			ContentType: a.ContentType,
+			ContentID:   a.ContentID,
+			Data:        a.Data,
+		})
+	}
+	raw, err := b.Build(mm)
+	if err != nil {
+		return nil, Permanent(err)
+	}
+	return raw, nil
+}
+
+// parseAddress returns s as an address, or as a bare address when it does
+// not parse.
+func parseAddress(s string) mail.Address {
+	if addr, err := mail.ParseAddress(s); err == nil {
+		return *addr
+	}
+	return mail.Address{Address: s}
+}
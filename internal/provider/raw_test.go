@@ -0,0 +1,156 @@
+package provider_test
+
+import (
+	"encoding/base64"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/parhamds/Email-Operator/internal/message"
+	"github.com/parhamds/Email-Operator/internal/provider"
+)
+
+// part is a decoded MIME part, multipart parts list their children.
+type part struct {
+	contentType string
+	disposition string
+	contentID   string
+	body        string
+	parts       []part
+}
+
+func readPart(header map[string][]string, body io.Reader) part {
+	get := func(key string) string {
+		if values := header[key]; len(values) > 0 {
+			return values[0]
+		}
+		return ""
+	}
+	p := part{contentType: get("Content-Type"), disposition: get("Content-Disposition"), contentID: get("Content-Id")}
+	mediaType, params, err := mime.ParseMediaType(p.contentType)
+	Expect(err).NotTo(HaveOccurred())
+	if !strings.HasPrefix(mediaType, "multipart/") {
+		data, err := io.ReadAll(body)
+		Expect(err).NotTo(HaveOccurred())
+		if get("Content-Transfer-Encoding") == "base64" {
+			data, err = base64.StdEncoding.DecodeString(strings.ReplaceAll(string(data), "\r\n", ""))
+			Expect(err).NotTo(HaveOccurred())
+		}
+		p.body = string(data)
+		return p
+	}
+
+	mr := multipart.NewReader(body, params["boundary"])
+	for {
+		child, err := mr.NextPart()
+		if err == io.EOF {
+			break
+		}
+		Expect(err).NotTo(HaveOccurred())
+		p.parts = append(p.parts, readPart(child.Header, child))
+	}
+	return p
+}
+
+var _ = Describe("Raw messages", func() {
+	It("should relate inline attachments to the HTML body and mix in the others", func() {
+		raw, err := (&provider.Message{
+			From:    "sender@example.com",
+			To:      []mail.Address{{Address: "recipient@example.com"}},
+			Subject: "Invoice",
+			Body:    "Your invoice is attached.",
+			HTML:    `<p>Your invoice is attached.</p><img src="cid:logo">`,
+			Attachments: []provider.Attachment{
+				{Filename: "invoice.pdf", ContentType: "application/pdf", Data: []byte(strings.Repeat("%PDF", 40))},
+				{Filename: "logo.png", ContentType: "image/png", ContentID: "logo", Data: []byte("\x89PNG")},
+			},
+		}).Raw(&message.Builder{}, "<id@example.com>", time.Now())
+		Expect(err).NotTo(HaveOccurred())
+
+		msg, err := mail.ReadMessage(strings.NewReader(string(raw)))
+		Expect(err).NotTo(HaveOccurred())
+		root := readPart(msg.Header, msg.Body)
+
+		Expect(root.contentType).To(HavePrefix("multipart/mixed"))
+		Expect(root.parts).To(HaveLen(2))
+
+		alternative := root.parts[0]
+		Expect(alternative.contentType).To(HavePrefix("multipart/alternative"))
+		Expect(alternative.parts).To(HaveLen(2))
+		Expect(alternative.parts[0].body).To(Equal("Your invoice is attached.\r\n"))
+
+		related := alternative.parts[1]
+		Expect(related.contentType).To(HavePrefix("multipart/related"))
+		Expect(related.parts).To(HaveLen(2))
+		Expect(related.parts[0].contentType).To(Equal("text/html; charset=utf-8"))
+		Expect(related.parts[1].contentType).To(Equal("image/png; name=logo.png"))
+		Expect(related.parts[1].disposition).To(Equal("inline; filename=logo.png"))
+		Expect(related.parts[1].contentID).To(Equal("<logo>"))
+		Expect(related.parts[1].body).To(Equal("\x89PNG"))
+
+		attachment := root.parts[1]
+		Expect(attachment.contentType).To(Equal("application/pdf; name=invoice.pdf"))
+		Expect(attachment.disposition).To(Equal("attachment; filename=invoice.pdf"))
+		Expect(attachment.body).To(Equal(strings.Repeat("%PDF", 40)))
+	})
+
+	It("should attach inline attachments of plain text messages", func() {
+		raw, err := (&provider.Message{
+			From:        "sender@example.com",
+			To:          []mail.Address{{Address: "recipient@example.com"}},
+			Subject:     "Logo",
+			Body:        "Our logo.",
+			Attachments: []provider.Attachment{{Filename: "logo.png", ContentID: "logo", Data: []byte("\x89PNG")}},
+		}).Raw(&message.Builder{}, "<id@example.com>", time.Now())
+		Expect(err).NotTo(HaveOccurred())
+
+		msg, err := mail.ReadMessage(strings.NewReader(string(raw)))
+		Expect(err).NotTo(HaveOccurred())
+		root := readPart(msg.Header, msg.Body)
+		Expect(root.contentType).To(HavePrefix("multipart/mixed"))
+		Expect(root.parts).To(HaveLen(2))
+		Expect(root.parts[0].body).To(Equal("Our logo.\r\n"))
+		Expect(root.parts[1].contentType).To(Equal("application/octet-stream; name=logo.png"))
+	})
+	It("should write the reply address and custom headers", func() {
+		raw, err := (&provider.Message{
+			From:    `"Billing" <billing@example.com>`,
+			To:      []mail.Address{{Address: "recipient@example.com"}},
+			Subject: "Invoice",
+			Body:    "Your invoice.",
+			ReplyTo: "Support <support@example.com>",
+			Headers: []provider.Header{{Name: "X-Campaign", Value: "spring"}, {Name: "X-Note", Value: "für dich"}},
+		}).Raw(&message.Builder{}, "<id@example.com>", time.Now())
+		Expect(err).NotTo(HaveOccurred())
+
+		msg, err := mail.ReadMessage(strings.NewReader(string(raw)))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(msg.Header.Get("From")).To(Equal(`"Billing" <billing@example.com>`))
+		Expect(msg.Header.Get("Reply-To")).To(Equal(`"Support" <support@example.com>`))
+		Expect(msg.Header.Get("X-Campaign")).To(Equal("spring"))
+		note, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("X-Note"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(note).To(Equal("für dich"))
+	})
+
+	It("should report messages that cannot be built as permanent errors", func() {
+		_, err := (&provider.Message{
+			From:    "sender@example.com",
+			Headers: []provider.Header{{Name: "X Campaign", Value: "spring"}},
+		}).Raw(&message.Builder{}, "<id@example.com>", time.Now())
+		Expect(err).To(MatchError(`invalid header name "X Campaign"`))
+		Expect(provider.IsPermanent(err)).To(BeTrue())
+	})
+
+	It("should protect headers set by the operator", func() {
+		Expect(provider.IsProtectedHeader("message-id")).To(BeTrue())
+		Expect(provider.IsProtectedHeader("Reply-To")).To(BeTrue())
+		Expect(provider.IsProtectedHeader("X-Campaign")).To(BeFalse())
+	})
+})
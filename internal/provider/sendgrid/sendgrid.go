@@ -0,0 +1,222 @@
+package sendgrid
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/parhamds/Email-Operator/internal/provider"
+)
+
+// Name is the EmailSenderConfig provider value of this provider.
+const Name = "SendGrid"
+
+// DefaultBaseURL is the SendGrid API base URL used when none is configured.
+const DefaultBaseURL = "https://api.sendgrid.com"
+
+func init() {
+	provider.Register(Name, New)
+}
+
+type sendGrid struct {
+	baseURL  string
+	apiToken string
+	client   *http.Client
+	timeout  time.Duration
+}
+
+// New returns a provider sending through the SendGrid v3 Mail Send API.
+func New(cfg provider.Config) (provider.Provider, error) {
+	client, err := cfg.Transport.HTTPClient()
+	if err != nil {
+		return nil, err
+	}
+	apiToken, err := cfg.SecretValue("apiToken")
+	if err != nil {
+		return nil, err
+	}
+	baseURL := DefaultBaseURL
+	if cfg.Spec.SendGrid != nil && cfg.Spec.SendGrid.BaseURL != "" {
+		baseURL = cfg.Spec.SendGrid.BaseURL
+	}
+	return &sendGrid{
+		baseURL:  strings.TrimSuffix(baseURL, "/"),
+		apiToken: apiToken,
+		client:   client,
+		timeout:  cfg.Timeout(),
+	}, nil
+}
+
+func (p *sendGrid) Validate() error {
+	if p.apiToken == "" {
+		return fmt.Errorf("API token must not be empty")
+	}
+	return nil
+}
+
+func (p *sendGrid) Capabilities() provider.Capabilities {
+	return provider.Capabilities{Attachments: true, StoredTemplates: true, Headers: true, Tags: true, MaxTags: 10, Metadata: true}
+}
+
+type address struct {
+	Email string `json:"email"`
+	Name  string `json:"name,omitempty"`
+}
+
+func newAddress(s string) address {
+	addr, err := provider.ParseAddress(s)
+	if err != nil {
+		return address{Email: s}
+	}
+	return address{Email: addr.Address, Name: addr.Name}
+}
+
+func addresses(addrs []mail.Address) []address {
+	out := make([]address, len(addrs))
+	for i, addr := range addrs {
+		out[i] = address{Email: addr.Address, Name: addr.Name}
+	}
+	return out
+}
+
+type personalization struct {
+	To                  []address         `json:"to"`
+	Cc                  []address         `json:"cc,omitempty"`
+	Bcc                 []address         `json:"bcc,omitempty"`
+	DynamicTemplateData map[string]string `json:"dynamic_template_data,omitempty"`
+}
+
+type content struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+type attachment struct {
+	Content     string `json:"content"`
+	Type        string `json:"type,omitempty"`
+	Filename    string `json:"filename"`
+	Disposition string `json:"disposition"`
+	ContentID   string `json:"content_id,omitempty"`
+}
+
+func attachments(in []provider.Attachment) []attachment {
+	var out []attachment
+	for _, a := range in {
+		disposition := "attachment"
+		if a.Inline() {
+			disposition = "inline"
+		}
+		out = append(out, attachment{
+			Content:     base64.StdEncoding.EncodeToString(a.Data),
+			Type:        a.ContentType,
+			Filename:    a.Filename,
+			Disposition: disposition,
+			ContentID:   a.ContentID,
+		})
+	}
+	return out
+}
+
+type mailSendRequest struct {
+	Personalizations []personalization `json:"personalizations"`
+	From             address           `json:"from"`
+	Subject          string            `json:"subject,omitempty"`
+	Content          []content         `json:"content,omitempty"`
+	TemplateID       string            `json:"template_id,omitempty"`
+	Attachments      []attachment      `json:"attachments,omitempty"`
+	ReplyTo          *address          `json:"reply_to,omitempty"`
+	Headers          map[string]string `json:"headers,omitempty"`
+	Categories       []string          `json:"categories,omitempty"`
+	CustomArgs       map[string]string `json:"custom_args,omitempty"`
+}
+
+type errorResponse struct {
+	Errors []struct {
+		Message string `json:"message"`
+		Field   string `json:"field"`
+	} `json:"errors"`
+}
+
+func (p *sendGrid) Send(ctx context.Context, msg *provider.Message) (*provider.Result, error) {
+	sendCtx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+
+	request := mailSendRequest{
+		Personalizations: []personalization{{To: addresses(msg.To), Cc: addresses(msg.Cc), Bcc: addresses(msg.Bcc)}},
+		From:             newAddress(msg.From),
+		Subject:          msg.Subject,
+		Attachments:      attachments(msg.Attachments),
+		Categories:       msg.Tags,
+		CustomArgs:       msg.Metadata,
+	}
+	if msg.Template != nil {
+		// Dynamic templates hold their own content.
+		request.TemplateID = msg.Template.ID
+		request.Personalizations[0].DynamicTemplateData = msg.Template.Variables
+	} else {
+		request.Content = []content{{Type: "text/plain", Value: msg.Body}}
+		if msg.HTML != "" {
+			request.Content = append(request.Content, content{Type: "text/html", Value: msg.HTML})
+		}
+	}
+	if msg.ReplyTo != "" {
+		replyTo := newAddress(msg.ReplyTo)
+		request.ReplyTo = &replyTo
+	}
+	if len(msg.Headers) > 0 {
+		request.Headers = map[string]string{}
+		for _, h := range msg.Headers {
+			request.Headers[h.Name] = h.Value
+		}
+	}
+	payload, err := json.Marshal(request)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(sendCtx, http.MethodPost, p.baseURL+"/v3/mail/send", bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+p.apiToken)
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := p.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusAccepted && res.StatusCode != http.StatusOK {
+		return nil, responseError(res)
+	}
+
+	return &provider.Result{MessageID: res.Header.Get("X-Message-Id")}, nil
+}
+
+// responseError maps a SendGrid error body into an error.
+func responseError(res *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(res.Body, 64<<10))
+
+	var errRes errorResponse
+	if err := json.Unmarshal(body, &errRes); err != nil || len(errRes.Errors) == 0 {
+		return fmt.Errorf("error response from SendGrid: %s", res.Status)
+	}
+
+	messages := make([]string, 0, len(errRes.Errors))
+	for _, e := range errRes.Errors {
+		if e.Field != "" {
+			messages = append(messages, fmt.Sprintf("%s: %s", e.Field, e.Message))
+		} else {
+			messages = append(messages, e.Message)
+		}
+	}
+	return fmt.Errorf("error response from SendGrid: %s: %s", res.Status, strings.Join(messages, "; "))
+}
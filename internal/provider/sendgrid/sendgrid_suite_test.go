@@ -0,0 +1,14 @@
+package sendgrid_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestSendGrid(t *testing.T) {
+	RegisterFailHandler(Fail)
+
+	RunSpecs(t, "SendGrid Provider Suite")
+}
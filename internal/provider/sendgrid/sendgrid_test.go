@@ -0,0 +1,171 @@
+package sendgrid_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/mail"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	parhamv1 "github.com/parhamds/Email-Operator/api/v1"
+	"github.com/parhamds/Email-Operator/internal/provider"
+	"github.com/parhamds/Email-Operator/internal/provider/sendgrid"
+)
+
+var _ = Describe("SendGrid provider", func() {
+	var (
+		server   *httptest.Server
+		handler  http.HandlerFunc
+		received map[string]any
+		authz    string
+	)
+
+	BeforeEach(func() {
+		received = nil
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			Expect(r.URL.Path).To(Equal("/v3/mail/send"))
+			authz = r.Header.Get("Authorization")
+			Expect(json.NewDecoder(r.Body).Decode(&received)).To(Succeed())
+			handler(w, r)
+		}))
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	newProvider := func() provider.Provider {
+		p, err := sendgrid.New(provider.Config{
+			Spec: parhamv1.EmailSenderConfigSpec{
+				Provider:    sendgrid.Name,
+				SenderEmail: "sender@example.com",
+				SendGrid:    &parhamv1.SendGridConfig{BaseURL: server.URL + "/"},
+			},
+			SecretName: "sendgrid-token",
+			Secret:     map[string][]byte{"apiToken": []byte("SG.token")},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(p.Validate()).To(Succeed())
+		return p
+	}
+
+	msg := &provider.Message{
+		From:    "sender@example.com",
+		To:      []mail.Address{{Address: "recipient@example.com"}},
+		Subject: "Test Subject",
+		Body:    "Test Body",
+	}
+
+	It("should send the message and record the X-Message-Id header", func() {
+		handler = func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("X-Message-Id", "sg-message-id")
+			w.WriteHeader(http.StatusAccepted)
+		}
+
+		res, err := newProvider().Send(context.Background(), msg)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(res.MessageID).To(Equal("sg-message-id"))
+
+		Expect(authz).To(Equal("Bearer SG.token"))
+		Expect(received).To(HaveKeyWithValue("subject", "Test Subject"))
+		Expect(received).To(HaveKeyWithValue("from", HaveKeyWithValue("email", "sender@example.com")))
+		Expect(received["personalizations"]).To(ConsistOf(
+			HaveKeyWithValue("to", ConsistOf(HaveKeyWithValue("email", "recipient@example.com"))),
+		))
+		Expect(received["content"]).To(ConsistOf(SatisfyAll(
+			HaveKeyWithValue("type", "text/plain"),
+			HaveKeyWithValue("value", "Test Body"),
+		)))
+	})
+
+	It("should send the HTML body after the plain text body", func() {
+		handler = func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusAccepted)
+		}
+
+		htmlMsg := *msg
+		htmlMsg.HTML = "<p>Test Body</p>"
+		_, err := newProvider().Send(context.Background(), &htmlMsg)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(received["content"]).To(Equal([]any{
+			map[string]any{"type": "text/plain", "value": "Test Body"},
+			map[string]any{"type": "text/html", "value": "<p>Test Body</p>"},
+		}))
+	})
+
+	It("should send attachments base64 encoded", func() {
+		handler = func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusAccepted)
+		}
+
+		attachmentMsg := *msg
+		attachmentMsg.Attachments = []provider.Attachment{
+			{Filename: "report.csv", ContentType: "text/csv", Data: []byte("a,b\n")},
+			{Filename: "logo.png", ContentType: "image/png", ContentID: "logo", Data: []byte("\x89PNG")},
+		}
+		_, err := newProvider().Send(context.Background(), &attachmentMsg)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(received["attachments"]).To(Equal([]any{
+			map[string]any{"content": "YSxiCg==", "type": "text/csv", "filename": "report.csv", "disposition": "attachment"},
+			map[string]any{"content": "iVBORw==", "type": "image/png", "filename": "logo.png", "disposition": "inline", "content_id": "logo"},
+		}))
+	})
+
+	It("should send to the Cc and Bcc recipients with their names", func() {
+		handler = func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusAccepted)
+		}
+
+		listMsg := *msg
+		listMsg.To = []mail.Address{{Name: "Jane", Address: "jane@example.com"}}
+		listMsg.Cc = []mail.Address{{Address: "cc@example.com"}}
+		listMsg.Bcc = []mail.Address{{Address: "bcc@example.com"}}
+		_, err := newProvider().Send(context.Background(), &listMsg)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(received["personalizations"]).To(Equal([]any{map[string]any{
+			"to":  []any{map[string]any{"email": "jane@example.com", "name": "Jane"}},
+			"cc":  []any{map[string]any{"email": "cc@example.com"}},
+			"bcc": []any{map[string]any{"email": "bcc@example.com"}},
+		}}))
+	})
+
+	It("should send a dynamic template in place of the content", func() {
+		handler = func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusAccepted)
+		}
+
+		templateMsg := *msg
+		templateMsg.Subject, templateMsg.Body = "", ""
+		templateMsg.Template = &provider.StoredTemplate{ID: "d-0123456789abcdef", Variables: map[string]string{"name": "Jane"}}
+		_, err := newProvider().Send(context.Background(), &templateMsg)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(received).To(HaveKeyWithValue("template_id", "d-0123456789abcdef"))
+		Expect(received).NotTo(HaveKey("content"))
+		Expect(received).NotTo(HaveKey("subject"))
+		Expect(received["personalizations"]).To(ConsistOf(
+			HaveKeyWithValue("dynamic_template_data", map[string]any{"name": "Jane"}),
+		))
+	})
+
+	It("should map SendGrid error bodies into the error", func() {
+		handler = func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"errors":[{"message":"The from address does not match a verified Sender Identity.","field":"from","help":null}]}`))
+		}
+
+		_, err := newProvider().Send(context.Background(), msg)
+		Expect(err).To(MatchError("error response from SendGrid: 400 Bad Request: from: The from address does not match a verified Sender Identity."))
+	})
+
+	It("should fall back to the status when the error body is not JSON", func() {
+		handler = func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+
+		_, err := newProvider().Send(context.Background(), msg)
+		Expect(err).To(MatchError("error response from SendGrid: 401 Unauthorized"))
+	})
+})
@@ -0,0 +1,234 @@
+package ses
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/parhamds/Email-Operator/internal/message"
+	"github.com/parhamds/Email-Operator/internal/provider"
+)
+
+// Name is the EmailSenderConfig provider value of this provider.
+const Name = "SES"
+
+func init() {
+	provider.Register(Name, New)
+}
+
+type ses struct {
+	region   string
+	endpoint string
+	creds    credentials
+	client   *http.Client
+	builder  *message.Builder
+	now      func() time.Time
+	timeout  time.Duration
+}
+
+// New returns a provider sending through the Amazon SES v2 API.
+func New(cfg provider.Config) (provider.Provider, error) {
+	if cfg.Spec.SES == nil {
+		return nil, errors.New("ses configuration is required for the SES provider")
+	}
+	client, err := cfg.Transport.HTTPClient()
+	if err != nil {
+		return nil, err
+	}
+
+	p := &ses{
+		region:   cfg.Spec.SES.Region,
+		endpoint: strings.TrimSuffix(cfg.Spec.SES.Endpoint, "/"),
+		client:   client,
+		builder:  cfg.MessageBuilder(),
+		now:      time.Now,
+		timeout:  cfg.Timeout(),
+	}
+	if p.endpoint == "" {
+		p.endpoint = fmt.Sprintf("https://email.%s.amazonaws.com", p.region)
+	}
+
+	if p.creds.accessKeyID, err = cfg.SecretValue("accessKeyId"); err != nil {
+		return nil, err
+	}
+	if p.creds.secretAccessKey, err = cfg.SecretValue("secretAccessKey"); err != nil {
+		return nil, err
+	}
+	p.creds.sessionToken = string(cfg.Secret["sessionToken"])
+	return p, nil
+}
+
+func (p *ses) Validate() error {
+	if p.region == "" {
+		return errors.New("ses region must not be empty")
+	}
+	if p.creds.accessKeyID == "" || p.creds.secretAccessKey == "" {
+		return errors.New("ses access key must not be empty")
+	}
+	return nil
+}
+
+func (p *ses) Capabilities() provider.Capabilities {
+	return provider.Capabilities{Attachments: true, StoredTemplates: true, Headers: true, Metadata: true}
+}
+
+type sesContent struct {
+	Data    string `json:"Data"`
+	Charset string `json:"Charset,omitempty"`
+}
+
+type sendEmailRequest struct {
+	FromEmailAddress string `json:"FromEmailAddress"`
+	Destination      struct {
+		ToAddresses  []string `json:"ToAddresses"`
+		CcAddresses  []string `json:"CcAddresses,omitempty"`
+		BccAddresses []string `json:"BccAddresses,omitempty"`
+	} `json:"Destination"`
+	ReplyToAddresses []string `json:"ReplyToAddresses,omitempty"`
+	EmailTags        []tag    `json:"EmailTags,omitempty"`
+	Content          struct {
+		Simple   *simpleContent   `json:"Simple,omitempty"`
+		Raw      *rawContent      `json:"Raw,omitempty"`
+		Template *templateContent `json:"Template,omitempty"`
+	} `json:"Content"`
+}
+
+type simpleContent struct {
+	Subject sesContent `json:"Subject"`
+	Body    struct {
+		Text sesContent  `json:"Text"`
+		Html *sesContent `json:"Html,omitempty"`
+	} `json:"Body"`
+	Headers []tag `json:"Headers,omitempty"`
+}
+
+// tag is the name and value pair SES uses for both message tags and headers.
+type tag struct {
+	Name  string `json:"Name"`
+	Value string `json:"Value"`
+}
+
+// templateContent references a template stored in SES, TemplateData is a
+// JSON object.
+type templateContent struct {
+	TemplateName string `json:"TemplateName"`
+	TemplateData string `json:"TemplateData"`
+	Headers      []tag  `json:"Headers,omitempty"`
+}
+
+// rawContent carries a full MIME message, encoded as base64 by encoding/json.
+type rawContent struct {
+	Data []byte `json:"Data"`
+}
+
+type sendEmailResponse struct {
+	MessageID string `json:"MessageId"`
+}
+
+type errorResponse struct {
+	Message string `json:"message"`
+}
+
+func (p *ses) Send(ctx context.Context, msg *provider.Message) (*provider.Result, error) {
+	if msg.Template != nil && len(msg.Attachments) > 0 {
+		// Templated content has no attachments and raw content no template.
+		return nil, provider.Permanent(errors.New("SES does not support attachments with stored templates"))
+	}
+
+	sendCtx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+
+	var body sendEmailRequest
+	body.FromEmailAddress = msg.From
+	body.Destination.ToAddresses = provider.Addresses(msg.To)
+	body.Destination.CcAddresses = provider.Addresses(msg.Cc)
+	body.Destination.BccAddresses = provider.Addresses(msg.Bcc)
+	if msg.ReplyTo != "" {
+		body.ReplyToAddresses = []string{msg.ReplyTo}
+	}
+	for key, value := range msg.Metadata {
+		body.EmailTags = append(body.EmailTags, tag{Name: key, Value: value})
+	}
+	sort.Slice(body.EmailTags, func(i, j int) bool { return body.EmailTags[i].Name < body.EmailTags[j].Name })
+	switch {
+	case msg.Template != nil:
+		variables := msg.Template.Variables
+		if variables == nil {
+			variables = map[string]string{}
+		}
+		data, err := json.Marshal(variables)
+		if err != nil {
+			return nil, err
+		}
+		template := &templateContent{TemplateName: msg.Template.ID, TemplateData: string(data)}
+		for _, h := range msg.Headers {
+			template.Headers = append(template.Headers, tag{Name: h.Name, Value: h.Value})
+		}
+		body.Content.Template = template
+	case len(msg.Attachments) > 0:
+		// Simple content has no attachments, so the message is sent as MIME.
+		raw, err := msg.Raw(p.builder, p.builder.MessageID(msg.From), p.now())
+		if err != nil {
+			return nil, err
+		}
+		body.Content.Raw = &rawContent{Data: raw}
+	default:
+		simple := &simpleContent{Subject: sesContent{Data: msg.Subject, Charset: "UTF-8"}}
+		simple.Body.Text = sesContent{Data: msg.Body, Charset: "UTF-8"}
+		if msg.HTML != "" {
+			simple.Body.Html = &sesContent{Data: msg.HTML, Charset: "UTF-8"}
+		}
+		for _, h := range msg.Headers {
+			simple.Headers = append(simple.Headers, tag{Name: h.Name, Value: h.Value})
+		}
+		body.Content.Simple = simple
+	}
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(sendCtx, http.MethodPost, p.endpoint+"/v2/email/outbound-emails", bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	signV4(req, payload, p.creds, p.region, "ses", p.now())
+
+	res, err := p.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	resBody, _ := io.ReadAll(io.LimitReader(res.Body, 64<<10))
+	if res.StatusCode != http.StatusOK {
+		var errRes errorResponse
+		_ = json.Unmarshal(resBody, &errRes)
+		errType := res.Header.Get("X-Amzn-Errortype")
+		if i := strings.Index(errType, ":"); i >= 0 {
+			errType = errType[:i]
+		}
+		switch {
+		case errType != "" && errRes.Message != "":
+			return nil, fmt.Errorf("error response from SES: %s: %s: %s", res.Status, errType, errRes.Message)
+		case errRes.Message != "":
+			return nil, fmt.Errorf("error response from SES: %s: %s", res.Status, errRes.Message)
+		default:
+			return nil, fmt.Errorf("error response from SES: %s", res.Status)
+		}
+	}
+
+	var out sendEmailResponse
+	if err := json.Unmarshal(resBody, &out); err != nil {
+		return nil, fmt.Errorf("invalid response from SES: %v", err)
+	}
+	return &provider.Result{MessageID: out.MessageID}, nil
+}
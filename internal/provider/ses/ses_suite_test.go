@@ -0,0 +1,14 @@
+package ses
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestSES(t *testing.T) {
+	RegisterFailHandler(Fail)
+
+	RunSpecs(t, "SES Provider Suite")
+}
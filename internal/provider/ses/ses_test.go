@@ -0,0 +1,163 @@
+package ses
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/mail"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	parhamv1 "github.com/parhamds/Email-Operator/api/v1"
+	"github.com/parhamds/Email-Operator/internal/provider"
+)
+
+var _ = Describe("signV4", func() {
+	It("should match the get-vanilla test vector of the AWS SigV4 test suite", func() {
+		req, err := http.NewRequest(http.MethodGet, "https://example.amazonaws.com/", nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		creds := credentials{
+			accessKeyID:     "AKIDEXAMPLE",
+			secretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY",
+		}
+		signV4(req, nil, creds, "us-east-1", "service", time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC))
+
+		Expect(req.Header.Get("Authorization")).To(Equal("AWS4-HMAC-SHA256 " +
+			"Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, " +
+			"SignedHeaders=host;x-amz-date, " +
+			"Signature=5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31"))
+	})
+})
+
+var _ = Describe("SES provider", func() {
+	var (
+		server   *httptest.Server
+		handler  http.HandlerFunc
+		received sendEmailRequest
+		header   http.Header
+	)
+
+	BeforeEach(func() {
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			Expect(r.URL.Path).To(Equal("/v2/email/outbound-emails"))
+			header = r.Header.Clone()
+			received = sendEmailRequest{}
+			Expect(json.NewDecoder(r.Body).Decode(&received)).To(Succeed())
+			handler(w, r)
+		}))
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	newProvider := func(secret map[string][]byte) provider.Provider {
+		p, err := New(provider.Config{
+			Spec: parhamv1.EmailSenderConfigSpec{
+				Provider:    Name,
+				SenderEmail: "sender@example.com",
+				SES:         &parhamv1.SESConfig{Region: "eu-west-1", Endpoint: server.URL},
+			},
+			SecretName: "ses-credentials",
+			Secret:     secret,
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(p.Validate()).To(Succeed())
+		p.(*ses).now = func() time.Time { return time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC) }
+		return p
+	}
+
+	msg := &provider.Message{
+		From:    "sender@example.com",
+		To:      []mail.Address{{Address: "recipient@example.com"}},
+		Subject: "Test Subject",
+		Body:    "Test Body",
+	}
+
+	It("should sign the request and record the SES MessageId", func() {
+		handler = func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`{"MessageId":"0102018cc2f5-ses-id"}`))
+		}
+
+		res, err := newProvider(map[string][]byte{
+			"accessKeyId":     []byte("AKID"),
+			"secretAccessKey": []byte("secret"),
+			"sessionToken":    []byte("token"),
+		}).Send(context.Background(), msg)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(res.MessageID).To(Equal("0102018cc2f5-ses-id"))
+
+		Expect(header.Get("X-Amz-Date")).To(Equal("20240102T030405Z"))
+		Expect(header.Get("X-Amz-Security-Token")).To(Equal("token"))
+		Expect(header.Get("Authorization")).To(HavePrefix("AWS4-HMAC-SHA256 " +
+			"Credential=AKID/20240102/eu-west-1/ses/aws4_request, " +
+			"SignedHeaders=content-type;host;x-amz-date;x-amz-security-token, Signature="))
+
+		Expect(received.FromEmailAddress).To(Equal("sender@example.com"))
+		Expect(received.Destination.ToAddresses).To(ConsistOf("recipient@example.com"))
+		Expect(received.Content.Simple.Subject.Data).To(Equal("Test Subject"))
+		Expect(received.Content.Simple.Body.Text.Data).To(Equal("Test Body"))
+	})
+
+	It("should send messages with attachments as raw content", func() {
+		handler = func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`{"MessageId":"0102018cc2f5-ses-id"}`))
+		}
+
+		attachmentMsg := *msg
+		attachmentMsg.Attachments = []provider.Attachment{{Filename: "report.csv", ContentType: "text/csv", Data: []byte("a,b\n")}}
+		_, err := newProvider(map[string][]byte{
+			"accessKeyId":     []byte("AKID"),
+			"secretAccessKey": []byte("secret"),
+		}).Send(context.Background(), &attachmentMsg)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(received.Content.Simple).To(BeNil())
+		Expect(received.Content.Raw).NotTo(BeNil())
+		Expect(string(received.Content.Raw.Data)).To(ContainSubstring("Content-Type: multipart/mixed"))
+		Expect(string(received.Content.Raw.Data)).To(ContainSubstring("Content-Disposition: attachment; filename=report.csv"))
+	})
+
+	It("should reject stored templates with attachments", func() {
+		handler = func(w http.ResponseWriter, r *http.Request) {
+			Fail("the message should not be sent")
+		}
+
+		templateMsg := *msg
+		templateMsg.Template = &provider.StoredTemplate{ID: "welcome"}
+		templateMsg.Attachments = []provider.Attachment{{Filename: "report.csv", ContentType: "text/csv", Data: []byte("a,b\n")}}
+		_, err := newProvider(map[string][]byte{
+			"accessKeyId":     []byte("AKID"),
+			"secretAccessKey": []byte("secret"),
+		}).Send(context.Background(), &templateMsg)
+		Expect(err).To(MatchError("SES does not support attachments with stored templates"))
+		Expect(provider.IsPermanent(err)).To(BeTrue())
+	})
+
+	It("should surface SES error messages", func() {
+		handler = func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("X-Amzn-Errortype", "MessageRejected:http://internal.amazon.com/coral/com.amazonaws.sesv2/")
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"message":"Email address is not verified."}`))
+		}
+
+		_, err := newProvider(map[string][]byte{
+			"accessKeyId":     []byte("AKID"),
+			"secretAccessKey": []byte("secret"),
+		}).Send(context.Background(), msg)
+		Expect(err).To(MatchError("error response from SES: 400 Bad Request: MessageRejected: Email address is not verified."))
+		Expect(header.Get("X-Amz-Security-Token")).To(BeEmpty())
+	})
+
+	It("should require the access key in the secret", func() {
+		_, err := New(provider.Config{
+			Spec:       parhamv1.EmailSenderConfigSpec{SES: &parhamv1.SESConfig{Region: "eu-west-1"}},
+			SecretName: "ses-credentials",
+		})
+		Expect(err).To(MatchError("secret ses-credentials does not contain key accessKeyId"))
+	})
+})
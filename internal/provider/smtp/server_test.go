@@ -0,0 +1,180 @@
+package smtp
+
+import (
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"net/textproto"
+	"strings"
+	"sync"
+)
+
+// testServer is a minimal in-process SMTP server supporting STARTTLS,
+// implicit TLS and the PLAIN, LOGIN and CRAM-MD5 AUTH mechanisms.
+type testServer struct {
+	listener  net.Listener
+	tlsConfig *tls.Config
+	implicit  bool
+	username  string
+	password  string
+	// reject lists recipients refused with a 550 reply.
+	reject map[string]bool
+
+	mu       sync.Mutex
+	from     string
+	rcpt     []string
+	data     string
+	authed   bool
+	usedTLS  bool
+	mechUsed string
+}
+
+func newTestServer(tlsConfig *tls.Config, implicit bool) *testServer {
+	var l net.Listener
+	var err error
+	if implicit {
+		l, err = tls.Listen("tcp", "127.0.0.1:0", tlsConfig)
+	} else {
+		l, err = net.Listen("tcp", "127.0.0.1:0")
+	}
+	if err != nil {
+		panic(err)
+	}
+	s := &testServer{listener: l, tlsConfig: tlsConfig, implicit: implicit, username: "user", password: "secret"}
+	go s.serve()
+	return s
+}
+
+func (s *testServer) addr() (string, int32) {
+	a := s.listener.Addr().(*net.TCPAddr)
+	return a.IP.String(), int32(a.Port)
+}
+
+func (s *testServer) close() {
+	s.listener.Close()
+}
+
+func (s *testServer) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *testServer) handle(conn net.Conn) {
+	defer conn.Close()
+	isTLS := s.implicit
+	tp := textproto.NewConn(conn)
+	reply := func(format string, args ...any) {
+		_ = tp.PrintfLine(format, args...)
+	}
+
+	reply("220 test ESMTP")
+	for {
+		line, err := tp.ReadLine()
+		if err != nil {
+			return
+		}
+		verb, arg, _ := strings.Cut(line, " ")
+		switch strings.ToUpper(verb) {
+		case "EHLO", "HELO":
+			if s.tlsConfig != nil && !isTLS {
+				reply("250-test")
+				reply("250-STARTTLS")
+			} else {
+				reply("250-test")
+			}
+			reply("250 AUTH PLAIN LOGIN CRAM-MD5")
+		case "STARTTLS":
+			reply("220 ready to start TLS")
+			tlsConn := tls.Server(conn, s.tlsConfig)
+			if err := tlsConn.Handshake(); err != nil {
+				return
+			}
+			conn = tlsConn
+			tp = textproto.NewConn(conn)
+			isTLS = true
+		case "AUTH":
+			ok := s.auth(tp, arg)
+			if ok {
+				reply("235 2.7.0 Authentication successful")
+			} else {
+				reply("535 5.7.8 Authentication credentials invalid")
+			}
+		case "MAIL":
+			s.mu.Lock()
+			s.from = strings.TrimSuffix(strings.TrimPrefix(arg, "FROM:<"), ">")
+			s.usedTLS = isTLS
+			s.mu.Unlock()
+			reply("250 2.1.0 Ok")
+		case "RCPT":
+			rcpt := strings.TrimSuffix(strings.TrimPrefix(arg, "TO:<"), ">")
+			if s.reject[rcpt] {
+				reply("550 5.1.1 <%s>: Recipient address rejected: User unknown", rcpt)
+				continue
+			}
+			s.mu.Lock()
+			s.rcpt = append(s.rcpt, rcpt)
+			s.mu.Unlock()
+			reply("250 2.1.5 Ok")
+		case "DATA":
+			reply("354 End data with <CR><LF>.<CR><LF>")
+			data, err := tp.ReadDotBytes()
+			if err != nil {
+				return
+			}
+			s.mu.Lock()
+			s.data = string(data)
+			s.mu.Unlock()
+			reply("250 2.0.0 Ok: queued as QUEUE123")
+		case "QUIT":
+			reply("221 2.0.0 Bye")
+			return
+		default:
+			reply("502 5.5.2 Command not recognized")
+		}
+	}
+}
+
+func (s *testServer) auth(tp *textproto.Conn, arg string) bool {
+	mech, initial, _ := strings.Cut(arg, " ")
+	s.mu.Lock()
+	s.mechUsed = mech
+	s.mu.Unlock()
+
+	challenge := func(text string) string {
+		_ = tp.PrintfLine("334 %s", base64.StdEncoding.EncodeToString([]byte(text)))
+		line, _ := tp.ReadLine()
+		decoded, _ := base64.StdEncoding.DecodeString(line)
+		return string(decoded)
+	}
+
+	var ok bool
+	switch mech {
+	case "PLAIN":
+		decoded, _ := base64.StdEncoding.DecodeString(initial)
+		parts := strings.Split(string(decoded), "\x00")
+		ok = len(parts) == 3 && parts[1] == s.username && parts[2] == s.password
+	case "LOGIN":
+		user := challenge("Username:")
+		pass := challenge("Password:")
+		ok = user == s.username && pass == s.password
+	case "CRAM-MD5":
+		nonce := "<1896.697170952@test>"
+		resp := challenge(nonce)
+		mac := hmac.New(md5.New, []byte(s.password))
+		mac.Write([]byte(nonce))
+		ok = resp == fmt.Sprintf("%s %s", s.username, hex.EncodeToString(mac.Sum(nil)))
+	}
+	s.mu.Lock()
+	s.authed = ok
+	s.mu.Unlock()
+	return ok
+}
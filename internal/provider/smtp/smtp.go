@@ -0,0 +1,293 @@
+package smtp
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/parhamds/Email-Operator/internal/message"
+	"github.com/parhamds/Email-Operator/internal/provider"
+)
+
+// Name is the EmailSenderConfig provider value of this provider.
+const Name = "SMTP"
+
+// TLS modes supported by the provider.
+const (
+	TLSModeNone     = "None"
+	TLSModeSTARTTLS = "STARTTLS"
+	TLSModeImplicit = "Implicit"
+)
+
+// AUTH mechanisms supported by the provider.
+const (
+	AuthPlain   = "PLAIN"
+	AuthLogin   = "LOGIN"
+	AuthCRAMMD5 = "CRAM-MD5"
+)
+
+func init() {
+	provider.Register(Name, New)
+}
+
+type smtpProvider struct {
+	host      string
+	port      int32
+	tlsMode   string
+	auth      string
+	username  string
+	password  string
+	tlsConfig *tls.Config
+	builder   *message.Builder
+	timeout   time.Duration
+}
+
+// New returns a provider delivering messages to an SMTP relay.
+func New(cfg provider.Config) (provider.Provider, error) {
+	if cfg.Spec.SMTP == nil {
+		return nil, errors.New("smtp configuration is required for the SMTP provider")
+	}
+
+	p := &smtpProvider{
+		host:    cfg.Spec.SMTP.Host,
+		port:    cfg.Spec.SMTP.Port,
+		tlsMode: cfg.Spec.SMTP.TLSMode,
+		auth:    cfg.Spec.SMTP.AuthMechanism,
+		builder: cfg.MessageBuilder(),
+		timeout: cfg.Timeout(),
+	}
+	if p.tlsMode == "" {
+		p.tlsMode = TLSModeSTARTTLS
+	}
+	if p.port == 0 {
+		switch p.tlsMode {
+		case TLSModeImplicit:
+			p.port = 465
+		case TLSModeNone:
+			p.port = 25
+		default:
+			p.port = 587
+		}
+	}
+	tlsConfig, err := cfg.Transport.TLSConfig()
+	if err != nil {
+		return nil, err
+	}
+	if tlsConfig == nil {
+		tlsConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+	p.tlsConfig = tlsConfig.Clone()
+	p.tlsConfig.ServerName = p.host
+
+	if p.auth != "" {
+		if p.username, err = cfg.SecretValue("username"); err != nil {
+			return nil, err
+		}
+		if p.password, err = cfg.SecretValue("password"); err != nil {
+			return nil, err
+		}
+	}
+	return p, nil
+}
+
+func (p *smtpProvider) Validate() error {
+	if p.host == "" {
+		return errors.New("smtp host must not be empty")
+	}
+	switch p.tlsMode {
+	case TLSModeNone, TLSModeSTARTTLS, TLSModeImplicit:
+	default:
+		return fmt.Errorf("unsupported smtp TLS mode %q", p.tlsMode)
+	}
+	switch p.auth {
+	case "", AuthPlain, AuthLogin, AuthCRAMMD5:
+	default:
+		return fmt.Errorf("unsupported smtp AUTH mechanism %q", p.auth)
+	}
+	return nil
+}
+
+func (p *smtpProvider) Capabilities() provider.Capabilities {
+	return provider.Capabilities{Attachments: true, Headers: true}
+}
+
+func (p *smtpProvider) Send(ctx context.Context, msg *provider.Message) (*provider.Result, error) {
+	sendCtx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+
+	c, err := p.dial(sendCtx)
+	if err != nil {
+		return nil, err
+	}
+	defer c.Close()
+
+	if p.tlsMode == TLSModeSTARTTLS {
+		if ok, _ := c.Extension("STARTTLS"); !ok {
+			return nil, errors.New("smtp server does not support STARTTLS")
+		}
+		if err := c.StartTLS(p.tlsConfig); err != nil {
+			return nil, fmt.Errorf("STARTTLS failed: %v", err)
+		}
+	}
+
+	if p.auth != "" {
+		if err := c.Auth(p.smtpAuth()); err != nil {
+			return nil, fmt.Errorf("smtp authentication failed: %v", err)
+		}
+	}
+
+	messageID := p.builder.MessageID(msg.From)
+	raw, err := msg.Raw(p.builder, messageID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if err := c.Mail(provider.BareAddress(msg.From)); err != nil {
+		return nil, err
+	}
+	// A recipient refused by the relay does not fail the message as long as
+	// another recipient is accepted.
+	var rejected []provider.RejectedRecipient
+	recipients := msg.Recipients()
+	for _, rcpt := range recipients {
+		if err := c.Rcpt(rcpt.Address); err != nil {
+			var protoErr *textproto.Error
+			if !errors.As(err, &protoErr) {
+				return nil, err
+			}
+			rejected = append(rejected, provider.RejectedRecipient{Address: rcpt.Address, Reason: err.Error()})
+		}
+	}
+	if len(rejected) == len(recipients) {
+		return nil, fmt.Errorf("smtp server rejected all recipients: %s", rejected[0].Reason)
+	}
+	reply, err := data(c, raw)
+	if err != nil {
+		return nil, err
+	}
+	_ = c.Quit()
+
+	id := queueID(reply)
+	if id == "" {
+		id = messageID
+	}
+	return &provider.Result{MessageID: id, Rejected: rejected}, nil
+}
+
+func (p *smtpProvider) dial(ctx context.Context) (*smtp.Client, error) {
+	addr := net.JoinHostPort(p.host, strconv.Itoa(int(p.port)))
+
+	var conn net.Conn
+	var err error
+	if p.tlsMode == TLSModeImplicit {
+		dialer := &tls.Dialer{Config: p.tlsConfig}
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	} else {
+		var dialer net.Dialer
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	c, err := smtp.NewClient(conn, p.host)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+func (p *smtpProvider) smtpAuth() smtp.Auth {
+	switch p.auth {
+	case AuthLogin:
+		return &loginAuth{username: p.username, password: p.password, host: p.host}
+	case AuthCRAMMD5:
+		return smtp.CRAMMD5Auth(p.username, p.password)
+	default:
+		return smtp.PlainAuth("", p.username, p.password, p.host)
+	}
+}
+
+// data sends the DATA command and returns the text of the final reply, which
+// net/smtp's own Data method discards.
+func data(c *smtp.Client, raw []byte) (string, error) {
+	id, err := c.Text.Cmd("DATA")
+	if err != nil {
+		return "", err
+	}
+	c.Text.StartResponse(id)
+	_, _, err = c.Text.ReadResponse(354)
+	c.Text.EndResponse(id)
+	if err != nil {
+		return "", err
+	}
+
+	w := c.Text.DotWriter()
+	if _, err := w.Write(raw); err != nil {
+		return "", err
+	}
+	if err := w.Close(); err != nil {
+		return "", err
+	}
+	_, reply, err := c.Text.ReadResponse(250)
+	if err != nil {
+		return "", err
+	}
+	return reply, nil
+}
+
+// queueID extracts the queue ID from a reply such as "2.0.0 Ok: queued as 4F2B1".
+func queueID(reply string) string {
+	i := strings.Index(strings.ToLower(reply), "queued as ")
+	if i < 0 {
+		return ""
+	}
+	fields := strings.Fields(reply[i+len("queued as "):])
+	if len(fields) == 0 {
+		return ""
+	}
+	return fields[0]
+}
+
+// loginAuth implements the LOGIN mechanism, which net/smtp does not provide.
+type loginAuth struct {
+	username, password, host string
+}
+
+func (a *loginAuth) Start(server *smtp.ServerInfo) (string, []byte, error) {
+	if !server.TLS && !isLocalhost(server.Name) {
+		return "", nil, errors.New("unencrypted connection")
+	}
+	if server.Name != a.host {
+		return "", nil, errors.New("wrong host name")
+	}
+	return "LOGIN", nil, nil
+}
+
+func (a *loginAuth) Next(fromServer []byte, more bool) ([]byte, error) {
+	if !more {
+		return nil, nil
+	}
+	switch strings.ToLower(strings.TrimSpace(string(fromServer))) {
+	case "username:":
+		return []byte(a.username), nil
+	case "password:":
+		return []byte(a.password), nil
+	default:
+		return nil, fmt.Errorf("unexpected LOGIN challenge %q", fromServer)
+	}
+}
+
+func isLocalhost(name string) bool {
+	return name == "localhost" || name == "127.0.0.1" || name == "::1"
+}
@@ -0,0 +1,14 @@
+package smtp
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestSMTP(t *testing.T) {
+	RegisterFailHandler(Fail)
+
+	RunSpecs(t, "SMTP Provider Suite")
+}
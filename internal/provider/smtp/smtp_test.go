@@ -0,0 +1,173 @@
+package smtp
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+	"net/http/httptest"
+	"net/mail"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	parhamv1 "github.com/parhamds/Email-Operator/api/v1"
+	"github.com/parhamds/Email-Operator/internal/provider"
+)
+
+var _ = Describe("SMTP provider", func() {
+	var (
+		serverTLS *tls.Config
+		roots     *x509.CertPool
+		server    *testServer
+	)
+
+	BeforeEach(func() {
+		// Borrow the self-signed certificate of httptest, which is valid for 127.0.0.1.
+		hs := httptest.NewUnstartedServer(http.NotFoundHandler())
+		hs.StartTLS()
+		serverTLS = &tls.Config{Certificates: hs.TLS.Certificates}
+		roots = x509.NewCertPool()
+		roots.AddCert(hs.Certificate())
+		hs.Close()
+	})
+
+	AfterEach(func() {
+		server.close()
+	})
+
+	newProvider := func(tlsMode, auth string) provider.Provider {
+		host, port := server.addr()
+		p, err := New(provider.Config{
+			Spec: parhamv1.EmailSenderConfigSpec{
+				Provider:    Name,
+				SenderEmail: "sender@example.com",
+				SMTP: &parhamv1.SMTPConfig{
+					Host:          host,
+					Port:          port,
+					TLSMode:       tlsMode,
+					AuthMechanism: auth,
+				},
+			},
+			SecretName: "smtp-credentials",
+			Secret: map[string][]byte{
+				"username": []byte("user"),
+				"password": []byte("secret"),
+			},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(p.Validate()).To(Succeed())
+		p.(*smtpProvider).tlsConfig.RootCAs = roots
+		return p
+	}
+
+	send := func(p provider.Provider) (*provider.Result, error) {
+		return p.Send(context.Background(), &provider.Message{
+			From:    "sender@example.com",
+			To:      []mail.Address{{Address: "recipient@example.com"}},
+			Subject: "Grüße",
+			Body:    "Hello\nWorld",
+		})
+	}
+
+	for _, auth := range []string{AuthPlain, AuthLogin, AuthCRAMMD5} {
+		It("should send over STARTTLS with "+auth+" authentication", func() {
+			server = newTestServer(serverTLS, false)
+
+			res, err := send(newProvider(TLSModeSTARTTLS, auth))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(res.MessageID).To(Equal("QUEUE123"))
+
+			Expect(server.usedTLS).To(BeTrue())
+			Expect(server.authed).To(BeTrue())
+			Expect(server.mechUsed).To(Equal(auth))
+			Expect(server.from).To(Equal("sender@example.com"))
+			Expect(server.rcpt).To(ConsistOf("recipient@example.com"))
+			Expect(server.data).To(ContainSubstring("Subject: =?utf-8?b?R3LDvMOfZQ==?="))
+			Expect(server.data).To(ContainSubstring("Hello\nWorld"))
+		})
+	}
+
+	It("should send over implicit TLS", func() {
+		server = newTestServer(serverTLS, true)
+
+		res, err := send(newProvider(TLSModeImplicit, AuthPlain))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(res.MessageID).To(Equal("QUEUE123"))
+		Expect(server.usedTLS).To(BeTrue())
+	})
+
+	It("should send without TLS and authentication", func() {
+		server = newTestServer(nil, false)
+
+		res, err := send(newProvider(TLSModeNone, ""))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(res.MessageID).To(Equal("QUEUE123"))
+		Expect(server.usedTLS).To(BeFalse())
+		Expect(server.authed).To(BeFalse())
+	})
+
+	It("should report recipients rejected by the server", func() {
+		server = newTestServer(nil, false)
+		server.reject = map[string]bool{"unknown@example.com": true}
+
+		res, err := newProvider(TLSModeNone, "").Send(context.Background(), &provider.Message{
+			From:    "sender@example.com",
+			To:      []mail.Address{{Name: "Jane", Address: "jane@example.com"}, {Address: "unknown@example.com"}},
+			Cc:      []mail.Address{{Address: "cc@example.com"}},
+			Bcc:     []mail.Address{{Address: "bcc@example.com"}},
+			Subject: "Hello",
+			Body:    "Hello",
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(server.rcpt).To(Equal([]string{"jane@example.com", "cc@example.com", "bcc@example.com"}))
+		Expect(res.Rejected).To(HaveLen(1))
+		Expect(res.Rejected[0].Address).To(Equal("unknown@example.com"))
+		Expect(res.Rejected[0].Reason).To(ContainSubstring("User unknown"))
+		Expect(server.data).To(ContainSubstring("To: \"Jane\" <jane@example.com>, unknown@example.com\n"))
+		Expect(server.data).To(ContainSubstring("Cc: cc@example.com\n"))
+		Expect(server.data).NotTo(ContainSubstring("bcc@example.com"))
+	})
+
+	It("should fail when the server rejects every recipient", func() {
+		server = newTestServer(nil, false)
+		server.reject = map[string]bool{"recipient@example.com": true}
+
+		_, err := send(newProvider(TLSModeNone, ""))
+		Expect(err).To(MatchError(ContainSubstring("smtp server rejected all recipients")))
+	})
+
+	It("should fail when the server does not offer STARTTLS", func() {
+		server = newTestServer(nil, false)
+
+		_, err := send(newProvider(TLSModeSTARTTLS, ""))
+		Expect(err).To(MatchError(ContainSubstring("does not support STARTTLS")))
+	})
+
+	It("should report rejected credentials", func() {
+		server = newTestServer(serverTLS, false)
+		server.password = "other"
+
+		_, err := send(newProvider(TLSModeSTARTTLS, AuthLogin))
+		Expect(err).To(MatchError(ContainSubstring("smtp authentication failed")))
+	})
+
+	It("should require credentials when AUTH is configured", func() {
+		server = newTestServer(nil, false)
+
+		_, err := New(provider.Config{
+			Spec: parhamv1.EmailSenderConfigSpec{
+				SMTP: &parhamv1.SMTPConfig{Host: "localhost", AuthMechanism: AuthPlain},
+			},
+			SecretName: "smtp-credentials",
+		})
+		Expect(err).To(MatchError("secret smtp-credentials does not contain key username"))
+	})
+})
+
+var _ = Describe("queueID", func() {
+	It("should extract the queue ID from the DATA reply", func() {
+		Expect(queueID("2.0.0 Ok: queued as 4F2B1")).To(Equal("4F2B1"))
+		Expect(queueID("2.0.0 OK 1717 - gsmtp")).To(BeEmpty())
+	})
+})
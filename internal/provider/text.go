@@ -0,0 +1,121 @@
+package provider
+
+import (
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+var (
+	spaces     = regexp.MustCompile(`[ \t\r\n\f]+`)
+	blankLines = regexp.MustCompile(`\n{3,}`)
+)
+
+// HTMLToText renders an HTML body as the plain text alternative of a
+// message. Block elements start new lines, list items are bulleted and
+// links keep their target after the link text.
+func HTMLToText(s string) string {
+	var (
+		buf   strings.Builder
+		last  byte
+		skip  int
+		links []*link
+	)
+	// write appends text to the output and to the text of the open links.
+	write := func(text string) {
+		if text == "" {
+			return
+		}
+		buf.WriteString(text)
+		last = text[len(text)-1]
+		for _, l := range links {
+			l.text.WriteString(text)
+		}
+	}
+	newline := func(n int) {
+		write(strings.Repeat("\n", n))
+	}
+
+	z := html.NewTokenizer(strings.NewReader(s))
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			break
+		}
+		tok := z.Token()
+		switch tt {
+		case html.TextToken:
+			if skip > 0 {
+				continue
+			}
+			text := spaces.ReplaceAllString(tok.Data, " ")
+			if last == '\n' || buf.Len() == 0 {
+				text = strings.TrimLeft(text, " ")
+			}
+			write(text)
+		case html.StartTagToken, html.SelfClosingTagToken:
+			switch tok.DataAtom {
+			case atom.Head, atom.Script, atom.Style, atom.Title:
+				if tt == html.StartTagToken {
+					skip++
+				}
+			case atom.Br:
+				newline(1)
+			case atom.P, atom.Div, atom.Table, atom.Ul, atom.Ol, atom.Blockquote,
+				atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6, atom.Hr:
+				newline(2)
+			case atom.Tr:
+				newline(1)
+			case atom.Li:
+				newline(1)
+				write("- ")
+			case atom.A:
+				links = append(links, &link{href: attr(tok, "href")})
+			}
+		case html.EndTagToken:
+			switch tok.DataAtom {
+			case atom.Head, atom.Script, atom.Style, atom.Title:
+				if skip > 0 {
+					skip--
+				}
+			case atom.P, atom.Div, atom.Table, atom.Ul, atom.Ol, atom.Blockquote,
+				atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6:
+				newline(2)
+			case atom.Td, atom.Th:
+				write(" ")
+			case atom.A:
+				if len(links) == 0 {
+					continue
+				}
+				l := links[len(links)-1]
+				links = links[:len(links)-1]
+				if l.href != "" && !strings.HasPrefix(l.href, "#") && !strings.HasSuffix(l.text.String(), l.href) {
+					write(" (" + l.href + ")")
+				}
+			}
+		}
+	}
+
+	lines := strings.Split(buf.String(), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSpace(line)
+	}
+	return strings.TrimSpace(blankLines.ReplaceAllString(strings.Join(lines, "\n"), "\n\n"))
+}
+
+// link is an open link with the text written since it started.
+type link struct {
+	href string
+	text strings.Builder
+}
+
+func attr(tok html.Token, key string) string {
+	for _, a := range tok.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
@@ -0,0 +1,77 @@
+package provider_test
+
+import (
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/parhamds/Email-Operator/internal/message"
+	"github.com/parhamds/Email-Operator/internal/provider"
+)
+
+var _ = Describe("HTML bodies", func() {
+	It("should render HTML as plain text", func() {
+		text := provider.HTMLToText(`<html><head><title>Ignored</title><style>p { color: red; }</style></head>
+<body>
+  <h1>Your   invoice</h1>
+  <p>Hello&nbsp;Jane,<br>your invoice is <b>ready</b>.</p>
+  <ul><li>Amount: 10&euro;</li><li>Due: tomorrow</li></ul>
+  <p><a href="https://example.com/invoice">View invoice</a> or <a href="https://example.com">https://example.com</a></p>
+  <script>alert("ignored")</script>
+</body></html>`)
+		Expect(text).To(Equal("Your invoice\n\n" +
+			"Hello Jane,\nyour invoice is ready.\n\n" +
+			"- Amount: 10€\n- Due: tomorrow\n\n" +
+			"View invoice (https://example.com/invoice) or https://example.com"))
+	})
+
+	It("should compare the link target with the text of the link only", func() {
+		text := provider.HTMLToText(`<p>See https://example.com/docs, <a href="https://example.com/docs">the docs</a> or <a href="https://example.com/faq"><b>https://example.com/faq</b></a></p>`)
+		Expect(text).To(Equal("See https://example.com/docs, the docs (https://example.com/docs) or https://example.com/faq"))
+	})
+
+	It("should render large bodies", func() {
+		body := strings.Repeat(`<p>Line with <a href="https://example.com">a link</a></p>`, 20000)
+		text := provider.HTMLToText(body)
+		Expect(strings.Count(text, "a link (https://example.com)")).To(Equal(20000))
+	})
+
+	It("should render both bodies as multipart/alternative", func() {
+		raw, err := (&provider.Message{
+			From:    "sender@example.com",
+			To:      []mail.Address{{Address: "recipient@example.com"}},
+			Subject: "Invoice",
+			Body:    "Your invoice is ready.",
+			HTML:    "<p>Your invoice is <b>ready</b>.</p>",
+		}).Raw(&message.Builder{}, "<id@example.com>", time.Now())
+		Expect(err).NotTo(HaveOccurred())
+
+		msg, err := mail.ReadMessage(strings.NewReader(string(raw)))
+		Expect(err).NotTo(HaveOccurred())
+		mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(mediaType).To(Equal("multipart/alternative"))
+
+		var types, bodies []string
+		mr := multipart.NewReader(msg.Body, params["boundary"])
+		for {
+			part, err := mr.NextPart()
+			if err != nil {
+				break
+			}
+			body := new(strings.Builder)
+			_, err = io.Copy(body, part)
+			Expect(err).NotTo(HaveOccurred())
+			types = append(types, part.Header.Get("Content-Type"))
+			bodies = append(bodies, body.String())
+		}
+		Expect(types).To(Equal([]string{"text/plain; charset=utf-8", "text/html; charset=utf-8"}))
+		Expect(bodies).To(Equal([]string{"Your invoice is ready.\r\n", "<p>Your invoice is <b>ready</b>.</p>\r\n"}))
+	})
+})
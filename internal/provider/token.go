@@ -0,0 +1,77 @@
+package provider
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// tokenExpiryDelta is how long before its expiry a token is refreshed.
+const tokenExpiryDelta = time.Minute
+
+// Token is an OAuth2 access token.
+type Token struct {
+	AccessToken string
+	Expiry      time.Time
+}
+
+// TokenCache keeps access tokens across sends, since a provider is built for
+// every message.
+type TokenCache struct {
+	mu     sync.Mutex
+	tokens map[string]*tokenEntry
+	// Now returns the current time, it is replaced in tests.
+	Now func() time.Time
+}
+
+// tokenEntry is the token of a key. Its lock is held while the token is
+// fetched, so concurrent sends fetch it once while other keys stay
+// available.
+type tokenEntry struct {
+	lock  chan struct{}
+	token Token
+}
+
+// NewTokenCache returns an empty TokenCache.
+func NewTokenCache() *TokenCache {
+	return &TokenCache{tokens: map[string]*tokenEntry{}, Now: time.Now}
+}
+
+// Get returns the token cached under key, calling fetch when it is missing or
+// about to expire.
+func (c *TokenCache) Get(ctx context.Context, key string, fetch func(ctx context.Context, now time.Time) (Token, error)) (string, error) {
+	c.mu.Lock()
+	e, ok := c.tokens[key]
+	if !ok {
+		e = &tokenEntry{lock: make(chan struct{}, 1)}
+		c.tokens[key] = e
+	}
+	c.mu.Unlock()
+
+	select {
+	case e.lock <- struct{}{}:
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+	defer func() { <-e.lock }()
+
+	now := c.Now()
+	if now.Add(tokenExpiryDelta).Before(e.token.Expiry) {
+		return e.token.AccessToken, nil
+	}
+
+	t, err := fetch(ctx, now)
+	if err != nil {
+		return "", err
+	}
+	e.token = t
+	return t.AccessToken, nil
+}
+
+// Invalidate drops the token cached under key, for example after it has been
+// rejected.
+func (c *TokenCache) Invalidate(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.tokens, key)
+}
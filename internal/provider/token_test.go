@@ -0,0 +1,78 @@
+package provider_test
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/parhamds/Email-Operator/internal/provider"
+)
+
+var _ = Describe("Token cache", func() {
+	It("should not block other keys while a token is fetched", func() {
+		cache := provider.NewTokenCache()
+		release := make(chan struct{})
+		defer close(release)
+
+		go func() {
+			defer GinkgoRecover()
+			_, _ = cache.Get(context.Background(), "slow", func(ctx context.Context, now time.Time) (provider.Token, error) {
+				<-release
+				return provider.Token{AccessToken: "slow", Expiry: now.Add(time.Hour)}, nil
+			})
+		}()
+
+		Eventually(func() (string, error) {
+			ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+			defer cancel()
+			return cache.Get(ctx, "fast", func(ctx context.Context, now time.Time) (provider.Token, error) {
+				return provider.Token{AccessToken: "fast", Expiry: now.Add(time.Hour)}, nil
+			})
+		}).Should(Equal("fast"))
+	})
+
+	It("should fetch a token once for concurrent lookups of a key", func() {
+		cache := provider.NewTokenCache()
+		fetches := make(chan struct{}, 10)
+		fetch := func(ctx context.Context, now time.Time) (provider.Token, error) {
+			fetches <- struct{}{}
+			time.Sleep(10 * time.Millisecond)
+			return provider.Token{AccessToken: "token", Expiry: now.Add(time.Hour)}, nil
+		}
+
+		done := make(chan string, 5)
+		for i := 0; i < 5; i++ {
+			go func() {
+				token, _ := cache.Get(context.Background(), "key", fetch)
+				done <- token
+			}()
+		}
+		for i := 0; i < 5; i++ {
+			Eventually(done).Should(Receive(Equal("token")))
+		}
+		Expect(fetches).To(HaveLen(1))
+	})
+
+	It("should stop waiting for a token when the context is done", func() {
+		cache := provider.NewTokenCache()
+		release := make(chan struct{})
+		defer close(release)
+		started := make(chan struct{})
+
+		go func() {
+			_, _ = cache.Get(context.Background(), "key", func(ctx context.Context, now time.Time) (provider.Token, error) {
+				close(started)
+				<-release
+				return provider.Token{}, nil
+			})
+		}()
+		Eventually(started).Should(BeClosed())
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		_, err := cache.Get(ctx, "key", nil)
+		Expect(err).To(MatchError(context.DeadlineExceeded))
+	})
+})
@@ -0,0 +1,127 @@
+package provider
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+)
+
+// TransportOptions configures the connections providers open to their
+// backends.
+type TransportOptions struct {
+	// ProxyURL routes HTTP requests through a proxy.
+	ProxyURL      string
+	ProxyUsername string
+	ProxyPassword string
+	// CABundle holds PEM encoded certificates trusted in addition to the
+	// system roots.
+	CABundle []byte
+	// ClientCert and ClientKey hold the PEM encoded client certificate
+	// presented to servers requesting one.
+	ClientCert []byte
+	ClientKey  []byte
+}
+
+// IsZero reports whether o leaves every setting at its default.
+func (o TransportOptions) IsZero() bool {
+	return o.ProxyURL == "" && len(o.CABundle) == 0 && len(o.ClientCert) == 0 && len(o.ClientKey) == 0
+}
+
+// Merge returns o with the settings it leaves empty taken from defaults.
+func (o TransportOptions) Merge(defaults TransportOptions) TransportOptions {
+	if o.ProxyURL == "" {
+		o.ProxyURL, o.ProxyUsername, o.ProxyPassword = defaults.ProxyURL, defaults.ProxyUsername, defaults.ProxyPassword
+	}
+	if len(o.CABundle) == 0 {
+		o.CABundle = defaults.CABundle
+	}
+	if len(o.ClientCert) == 0 && len(o.ClientKey) == 0 {
+		o.ClientCert, o.ClientKey = defaults.ClientCert, defaults.ClientKey
+	}
+	return o
+}
+
+// TLSConfig returns the TLS configuration of o, nil when o does not change
+// the defaults.
+func (o TransportOptions) TLSConfig() (*tls.Config, error) {
+	if len(o.CABundle) == 0 && len(o.ClientCert) == 0 && len(o.ClientKey) == 0 {
+		return nil, nil
+	}
+	cfg := &tls.Config{MinVersion: tls.VersionTLS12}
+	if len(o.CABundle) > 0 {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(o.CABundle) {
+			return nil, errors.New("CA bundle does not contain any PEM encoded certificate")
+		}
+		cfg.RootCAs = pool
+	}
+	if len(o.ClientCert) > 0 || len(o.ClientKey) > 0 {
+		cert, err := tls.X509KeyPair(o.ClientCert, o.ClientKey)
+		if err != nil {
+			return nil, fmt.Errorf("invalid client certificate: %v", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+	return cfg, nil
+}
+
+var (
+	clientsMu sync.Mutex
+	clients   = map[string]*http.Client{}
+)
+
+// HTTPClient returns the HTTP client configured by o. Providers with the same
+// options share a client and therefore its connections.
+func (o TransportOptions) HTTPClient() (*http.Client, error) {
+	if o.IsZero() {
+		return http.DefaultClient, nil
+	}
+
+	key := o.key()
+	clientsMu.Lock()
+	defer clientsMu.Unlock()
+	if c, ok := clients[key]; ok {
+		return c, nil
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	tlsConfig, err := o.TLSConfig()
+	if err != nil {
+		return nil, err
+	}
+	if tlsConfig != nil {
+		transport.TLSClientConfig = tlsConfig
+	}
+	if o.ProxyURL != "" {
+		proxyURL, err := url.Parse(o.ProxyURL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy URL: %v", err)
+		}
+		if o.ProxyUsername != "" {
+			proxyURL.User = url.UserPassword(o.ProxyUsername, o.ProxyPassword)
+		}
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+
+	c := &http.Client{Transport: transport}
+	clients[key] = c
+	return c, nil
+}
+
+func (o TransportOptions) key() string {
+	h := sha256.New()
+	for _, part := range [][]byte{[]byte(o.ProxyURL), []byte(o.ProxyUsername), []byte(o.ProxyPassword), o.CABundle, o.ClientCert, o.ClientKey} {
+		fmt.Fprintf(h, "%d:", len(part))
+		h.Write(part)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
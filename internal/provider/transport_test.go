@@ -0,0 +1,121 @@
+package provider_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/parhamds/Email-Operator/internal/provider"
+)
+
+// newClientCert returns a self signed PEM encoded certificate and key.
+func newClientCert() ([]byte, []byte) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	Expect(err).NotTo(HaveOccurred())
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "email-operator"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	Expect(err).NotTo(HaveOccurred())
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	Expect(err).NotTo(HaveOccurred())
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+var _ = Describe("Transport", func() {
+	It("should use the default client without options", func() {
+		client, err := provider.TransportOptions{}.HTTPClient()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(client).To(BeIdenticalTo(http.DefaultClient))
+	})
+
+	It("should share the client of equal options", func() {
+		options := provider.TransportOptions{ProxyURL: "http://proxy.internal:3128"}
+		first, err := options.HTTPClient()
+		Expect(err).NotTo(HaveOccurred())
+		second, err := options.HTTPClient()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(second).To(BeIdenticalTo(first))
+	})
+
+	It("should send requests through an authenticated proxy", func() {
+		var target, authorization string
+		proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			target = r.URL.String()
+			authorization = r.Header.Get("Proxy-Authorization")
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		defer proxy.Close()
+
+		client, err := provider.TransportOptions{
+			ProxyURL:      proxy.URL,
+			ProxyUsername: "operator",
+			ProxyPassword: "secret",
+		}.HTTPClient()
+		Expect(err).NotTo(HaveOccurred())
+
+		res, err := client.Get("http://api.example.com/v3/mail/send")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(res.Body.Close()).To(Succeed())
+		Expect(res.StatusCode).To(Equal(http.StatusNoContent))
+		Expect(target).To(Equal("http://api.example.com/v3/mail/send"))
+		Expect(authorization).To(Equal("Basic b3BlcmF0b3I6c2VjcmV0"))
+	})
+
+	It("should trust the CA bundle and present the client certificate", func() {
+		clientCert, clientKey := newClientCert()
+		server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			Expect(r.TLS.PeerCertificates).To(HaveLen(1))
+			Expect(r.TLS.PeerCertificates[0].Subject.CommonName).To(Equal("email-operator"))
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		server.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert}
+		server.StartTLS()
+		defer server.Close()
+
+		caBundle := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+
+		_, err := http.DefaultClient.Get(server.URL)
+		Expect(err).To(HaveOccurred())
+
+		client, err := provider.TransportOptions{
+			CABundle:   caBundle,
+			ClientCert: clientCert,
+			ClientKey:  clientKey,
+		}.HTTPClient()
+		Expect(err).NotTo(HaveOccurred())
+
+		res, err := client.Get(server.URL)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(res.Body.Close()).To(Succeed())
+		Expect(res.StatusCode).To(Equal(http.StatusNoContent))
+	})
+
+	It("should report an invalid CA bundle", func() {
+		_, err := provider.TransportOptions{CABundle: []byte("not a certificate")}.HTTPClient()
+		Expect(err).To(MatchError(ContainSubstring("CA bundle does not contain any PEM encoded certificate")))
+	})
+
+	It("should take unset options from the defaults", func() {
+		defaults := provider.TransportOptions{ProxyURL: "http://proxy.internal:3128", CABundle: []byte("ca")}
+		options := provider.TransportOptions{CABundle: []byte("own ca")}.Merge(defaults)
+		Expect(options.ProxyURL).To(Equal("http://proxy.internal:3128"))
+		Expect(options.CABundle).To(Equal([]byte("own ca")))
+	})
+})
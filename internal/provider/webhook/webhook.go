@@ -0,0 +1,204 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"text/template"
+	"time"
+
+	"k8s.io/client-go/util/jsonpath"
+
+	"github.com/parhamds/Email-Operator/internal/message"
+	"github.com/parhamds/Email-Operator/internal/provider"
+)
+
+// Name is the EmailSenderConfig provider value of this provider.
+const Name = "Webhook"
+
+const defaultBodyTemplate = `{"from":{{json .From}},"to":{{json .To}}{{if .Cc}},"cc":{{json .Cc}}{{end}}{{if .Bcc}},"bcc":{{json .Bcc}}{{end}},"subject":{{json .Subject}},"body":{{json .Body}}{{if .HTML}},"html":{{json .HTML}}{{end}}{{if .ReplyTo}},"replyTo":{{json .ReplyTo}}{{end}}{{if .Headers}},"headers":{{json .Headers}}{{end}}{{if .Tags}},"tags":{{json .Tags}}{{end}}{{if .Metadata}},"metadata":{{json .Metadata}}{{end}}}`
+
+func init() {
+	provider.Register(Name, New)
+}
+
+type webhook struct {
+	url           string
+	method        string
+	headers       http.Header
+	body          *template.Template
+	messageIDPath *jsonpath.JSONPath
+	client        *http.Client
+	timeout       time.Duration
+}
+
+var funcs = template.FuncMap{
+	"json": func(v any) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	},
+}
+
+// New returns a provider sending every message as an HTTP request.
+func New(cfg provider.Config) (provider.Provider, error) {
+	spec := cfg.Spec.Webhook
+	if spec == nil {
+		return nil, errors.New("webhook configuration is required for the Webhook provider")
+	}
+	client, err := cfg.Transport.HTTPClient()
+	if err != nil {
+		return nil, err
+	}
+
+	p := &webhook{
+		url:     spec.URL,
+		method:  spec.Method,
+		headers: http.Header{},
+		client:  client,
+		timeout: cfg.Timeout(),
+	}
+	if p.method == "" {
+		p.method = http.MethodPost
+	}
+
+	for _, h := range spec.Headers {
+		value := h.Value
+		if h.SecretKey != "" {
+			var err error
+			if value, err = cfg.SecretValue(h.SecretKey); err != nil {
+				return nil, err
+			}
+		}
+		p.headers.Add(h.Name, value)
+	}
+	if p.headers.Get("Content-Type") == "" {
+		p.headers.Set("Content-Type", "application/json")
+	}
+
+	bodyTemplate := spec.BodyTemplate
+	if bodyTemplate == "" {
+		bodyTemplate = defaultBodyTemplate
+	}
+	if p.body, err = template.New("body").Funcs(funcs).Option("missingkey=error").Parse(bodyTemplate); err != nil {
+		return nil, fmt.Errorf("invalid body template: %v", err)
+	}
+
+	if spec.MessageIDPath != "" {
+		// A path without braces would be parsed as literal text.
+		path := spec.MessageIDPath
+		if !strings.HasPrefix(path, "{") {
+			path = "{" + path + "}"
+		}
+		p.messageIDPath = jsonpath.New("messageId")
+		if err := p.messageIDPath.Parse(path); err != nil {
+			return nil, fmt.Errorf("invalid message id path: %v", err)
+		}
+	}
+	return p, nil
+}
+
+func (p *webhook) Validate() error {
+	if p.url == "" {
+		return errors.New("webhook url must not be empty")
+	}
+	if !strings.HasPrefix(p.url, "http://") && !strings.HasPrefix(p.url, "https://") {
+		return fmt.Errorf("webhook url %q must use http or https", p.url)
+	}
+	return nil
+}
+
+func (p *webhook) Capabilities() provider.Capabilities {
+	return provider.Capabilities{Headers: true, Tags: true, Metadata: true}
+}
+
+// templateData is the value the body template is rendered with. Recipients
+// are comma separated address lists, as in message headers.
+type templateData struct {
+	From     string
+	To       string
+	Cc       string
+	Bcc      string
+	Subject  string
+	Body     string
+	HTML     string
+	ReplyTo  string
+	Headers  map[string]string
+	Tags     []string
+	Metadata map[string]string
+}
+
+func (p *webhook) Send(ctx context.Context, msg *provider.Message) (*provider.Result, error) {
+	sendCtx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+
+	data := templateData{
+		From:     msg.From,
+		To:       message.FormatAddressList(msg.To),
+		Cc:       message.FormatAddressList(msg.Cc),
+		Bcc:      message.FormatAddressList(msg.Bcc),
+		Subject:  msg.Subject,
+		Body:     msg.Body,
+		HTML:     msg.HTML,
+		ReplyTo:  msg.ReplyTo,
+		Tags:     msg.Tags,
+		Metadata: msg.Metadata,
+	}
+	if len(msg.Headers) > 0 {
+		data.Headers = map[string]string{}
+		for _, h := range msg.Headers {
+			data.Headers[h.Name] = h.Value
+		}
+	}
+
+	var body bytes.Buffer
+	if err := p.body.Execute(&body, data); err != nil {
+		return nil, provider.Permanent(fmt.Errorf("unable to render body template: %v", err))
+	}
+
+	req, err := http.NewRequestWithContext(sendCtx, p.method, p.url, &body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header = p.headers.Clone()
+
+	res, err := p.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	resBody, _ := io.ReadAll(io.LimitReader(res.Body, 1<<20))
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		if text := strings.TrimSpace(string(resBody)); text != "" {
+			if len(text) > 256 {
+				text = text[:256]
+			}
+			return nil, fmt.Errorf("error response from webhook: %s: %s", res.Status, text)
+		}
+		return nil, fmt.Errorf("error response from webhook: %s", res.Status)
+	}
+
+	// The message has been accepted at this point, so a response without a
+	// message id is not reported as a failed delivery.
+	return &provider.Result{MessageID: p.extractMessageID(resBody)}, nil
+}
+
+func (p *webhook) extractMessageID(body []byte) string {
+	if p.messageIDPath == nil {
+		return ""
+	}
+	var data any
+	if err := json.Unmarshal(body, &data); err != nil {
+		return ""
+	}
+	var out bytes.Buffer
+	if err := p.messageIDPath.Execute(&out, data); err != nil {
+		return ""
+	}
+	return out.String()
+}
@@ -0,0 +1,135 @@
+package webhook_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/mail"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	parhamv1 "github.com/parhamds/Email-Operator/api/v1"
+	"github.com/parhamds/Email-Operator/internal/provider"
+	"github.com/parhamds/Email-Operator/internal/provider/webhook"
+)
+
+var _ = Describe("Webhook provider", func() {
+	var (
+		server   *httptest.Server
+		method   string
+		header   http.Header
+		received string
+		response string
+		status   int
+	)
+
+	BeforeEach(func() {
+		status = http.StatusOK
+		response = `{"data":{"id":"gw-42"}}`
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			method = r.Method
+			header = r.Header.Clone()
+			body, err := io.ReadAll(r.Body)
+			Expect(err).NotTo(HaveOccurred())
+			received = string(body)
+			w.WriteHeader(status)
+			_, _ = w.Write([]byte(response))
+		}))
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	newProvider := func(spec parhamv1.WebhookConfig) provider.Provider {
+		spec.URL = server.URL + "/notify"
+		p, err := webhook.New(provider.Config{
+			Spec: parhamv1.EmailSenderConfigSpec{
+				Provider:    webhook.Name,
+				SenderEmail: "sender@example.com",
+				Webhook:     &spec,
+			},
+			SecretName: "gateway-credentials",
+			Secret:     map[string][]byte{"token": []byte("s3cr3t")},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(p.Validate()).To(Succeed())
+		return p
+	}
+
+	msg := &provider.Message{
+		From:    "sender@example.com",
+		To:      []mail.Address{{Address: "recipient@example.com"}},
+		Subject: `Say "hi"`,
+		Body:    "Line 1\nLine 2",
+	}
+
+	It("should render the body template and extract the message id", func() {
+		p := newProvider(parhamv1.WebhookConfig{
+			Method: http.MethodPut,
+			Headers: []parhamv1.WebhookHeader{
+				{Name: "Authorization", SecretKey: "token"},
+				{Name: "X-Source", Value: "email-operator"},
+			},
+			BodyTemplate:  `{"recipient":{{json .To}},"title":{{json .Subject}},"text":{{json .Body}}}`,
+			MessageIDPath: "{.data.id}",
+		})
+
+		res, err := p.Send(context.Background(), msg)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(res.MessageID).To(Equal("gw-42"))
+
+		Expect(method).To(Equal(http.MethodPut))
+		Expect(header.Get("Authorization")).To(Equal("s3cr3t"))
+		Expect(header.Get("X-Source")).To(Equal("email-operator"))
+		Expect(header.Get("Content-Type")).To(Equal("application/json"))
+		Expect(received).To(MatchJSON(`{"recipient":"recipient@example.com","title":"Say \"hi\"","text":"Line 1\nLine 2"}`))
+	})
+
+	It("should extract the message id with a path written without braces", func() {
+		res, err := newProvider(parhamv1.WebhookConfig{MessageIDPath: ".data.id"}).Send(context.Background(), msg)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(res.MessageID).To(Equal("gw-42"))
+	})
+
+	It("should send a JSON object by default", func() {
+		res, err := newProvider(parhamv1.WebhookConfig{}).Send(context.Background(), msg)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(res.MessageID).To(BeEmpty())
+
+		Expect(method).To(Equal(http.MethodPost))
+		Expect(received).To(MatchJSON(`{"from":"sender@example.com","to":"recipient@example.com","subject":"Say \"hi\"","body":"Line 1\nLine 2"}`))
+	})
+
+	It("should report non-2xx responses", func() {
+		status = http.StatusBadGateway
+		response = "upstream unavailable"
+
+		_, err := newProvider(parhamv1.WebhookConfig{}).Send(context.Background(), msg)
+		Expect(err).To(MatchError("error response from webhook: 502 Bad Gateway: upstream unavailable"))
+	})
+
+	It("should reject headers referencing missing secret keys", func() {
+		_, err := webhook.New(provider.Config{
+			Spec: parhamv1.EmailSenderConfigSpec{
+				Webhook: &parhamv1.WebhookConfig{
+					URL:     "https://gateway.example.com",
+					Headers: []parhamv1.WebhookHeader{{Name: "Authorization", SecretKey: "missing"}},
+				},
+			},
+			SecretName: "gateway-credentials",
+		})
+		Expect(err).To(MatchError("secret gateway-credentials does not contain key missing"))
+	})
+
+	It("should reject an invalid body template", func() {
+		_, err := webhook.New(provider.Config{
+			Spec: parhamv1.EmailSenderConfigSpec{
+				Webhook: &parhamv1.WebhookConfig{URL: "https://gateway.example.com", BodyTemplate: "{{.To"},
+			},
+		})
+		Expect(err).To(MatchError(ContainSubstring("invalid body template")))
+	})
+})
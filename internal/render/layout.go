@@ -0,0 +1,41 @@
+package render
+
+import (
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"sort"
+	"strings"
+)
+
+//go:embed layouts/*.html
+var layoutFiles embed.FS
+
+var layouts = htmltemplate.Must(htmltemplate.ParseFS(layoutFiles, "layouts/*.html"))
+
+// Layouts returns the names of the built-in layouts.
+func Layouts() []string {
+	var names []string
+	for _, t := range layouts.Templates() {
+		names = append(names, strings.TrimSuffix(t.Name(), ".html"))
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Layout wraps an HTML body in the named layout, a complete HTML document
+// with subject as its title.
+func Layout(name, subject, body string) (string, error) {
+	t := layouts.Lookup(name + ".html")
+	if t == nil {
+		return "", fmt.Errorf("unknown layout %q, must be one of %s", name, strings.Join(Layouts(), ", "))
+	}
+	var out strings.Builder
+	if err := t.Execute(&out, struct {
+		Subject string
+		Body    htmltemplate.HTML
+	}{Subject: subject, Body: htmltemplate.HTML(body)}); err != nil {
+		return "", err
+	}
+	return out.String(), nil
+}
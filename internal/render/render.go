@@ -0,0 +1,64 @@
+// Package render renders the subject and bodies of an email from Go
+// templates.
+package render
+
+import (
+	htmltemplate "html/template"
+	"strings"
+	texttemplate "text/template"
+)
+
+// Content is the subject and bodies of an email.
+type Content struct {
+	Subject string
+	Text    string
+	HTML    string
+}
+
+// Render executes the subject and the plain text body as text/template and
+// the HTML body as html/template, which escapes the values it inserts. A
+// value missing from data is an error.
+func Render(content Content, data map[string]any) (Content, error) {
+	var out Content
+	var err error
+	if out.Subject, err = renderText("subject", content.Subject, data); err != nil {
+		return Content{}, err
+	}
+	if out.Text, err = renderText("body", content.Text, data); err != nil {
+		return Content{}, err
+	}
+	if out.HTML, err = renderHTML("html", content.HTML, data); err != nil {
+		return Content{}, err
+	}
+	return out, nil
+}
+
+func renderText(name, source string, data map[string]any) (string, error) {
+	if source == "" {
+		return "", nil
+	}
+	t, err := texttemplate.New(name).Option("missingkey=error").Parse(source)
+	if err != nil {
+		return "", err
+	}
+	var out strings.Builder
+	if err := t.Execute(&out, data); err != nil {
+		return "", err
+	}
+	return out.String(), nil
+}
+
+func renderHTML(name, source string, data map[string]any) (string, error) {
+	if source == "" {
+		return "", nil
+	}
+	t, err := htmltemplate.New(name).Option("missingkey=error").Parse(source)
+	if err != nil {
+		return "", err
+	}
+	var out strings.Builder
+	if err := t.Execute(&out, data); err != nil {
+		return "", err
+	}
+	return out.String(), nil
+}
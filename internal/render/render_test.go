@@ -0,0 +1,59 @@
+package render_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/parhamds/Email-Operator/internal/render"
+)
+
+var _ = Describe("Render", func() {
+	data := map[string]any{
+		"name":       "Jane <jane@example.com>",
+		"image":      "nginx:1.27",
+		"deployment": map[string]any{"spec": map[string]any{"replicas": int64(3)}},
+	}
+
+	It("should render the subject and bodies", func() {
+		content, err := render.Render(render.Content{
+			Subject: "Deployed {{ .image }}",
+			Text:    "Hello {{ .name }}, {{ .deployment.spec.replicas }} replicas run {{ .image }}.",
+			HTML:    "<p>Hello {{ .name }}</p>",
+		}, data)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(content.Subject).To(Equal("Deployed nginx:1.27"))
+		Expect(content.Text).To(Equal("Hello Jane <jane@example.com>, 3 replicas run nginx:1.27."))
+		Expect(content.HTML).To(Equal("<p>Hello Jane &lt;jane@example.com&gt;</p>"))
+	})
+
+	It("should leave empty bodies empty", func() {
+		content, err := render.Render(render.Content{Subject: "Hello", Text: "Hello"}, data)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(content.HTML).To(BeEmpty())
+	})
+
+	It("should fail on missing values", func() {
+		_, err := render.Render(render.Content{Subject: "Hello {{ .missing }}"}, data)
+		Expect(err).To(MatchError(ContainSubstring(`template: subject:1:9: executing "subject" at <.missing>: map has no entry for key "missing"`)))
+	})
+
+	It("should fail on invalid templates", func() {
+		_, err := render.Render(render.Content{Subject: "Hello", Text: "Hello {{ .name"}, data)
+		Expect(err).To(MatchError(ContainSubstring("template: body:1")))
+	})
+})
+
+var _ = Describe("Layout", func() {
+	It("should wrap the body in the layout", func() {
+		html, err := render.Layout("card", "Release <1.4>", "<p>Released</p>")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(html).To(HavePrefix("<!DOCTYPE html>"))
+		Expect(html).To(ContainSubstring("<title>Release &lt;1.4&gt;</title>"))
+		Expect(html).To(ContainSubstring("<p>Released</p>"))
+	})
+
+	It("should fail on unknown layouts", func() {
+		_, err := render.Layout("fancy", "Hello", "<p>Hello</p>")
+		Expect(err).To(MatchError(`unknown layout "fancy", must be one of basic, card`))
+	})
+})